@@ -6,6 +6,7 @@ require (
 	github.com/gdamore/tcell/v2 v2.8.1
 	github.com/rivo/tview v0.42.0
 	golang.design/x/clipboard v0.7.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
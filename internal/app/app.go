@@ -2,6 +2,8 @@ package app
 
 import (
 	"fmt"
+	"time"
+
 	"github.com/rivo/tview"
 )
 
@@ -12,11 +14,18 @@ type App struct {
 	textArea              *tview.TextArea
 	highlightedView       *tview.TextView
 	matchView             *tview.TextView
+	matchTable            *tview.Table
+	matchPages            *tview.Pages // Shows the match view or the table
 	helpHintView          *tview.TextView
+	suiteView             *tview.TextView
+	freqView              *tview.TextView
+	statsView             *tview.TextView
+	timelineList          *tview.List
 	flex                  *tview.Flex
+	bottomPane            *tview.Flex
 	pages                 *tview.Pages
 	modalPages            *tview.Pages
-	helpView              *tview.TextView // For the help screen
+	libraryView           *LibraryView // For the help screen
 	focusables            []tview.Primitive
 	matches               [][]string // Store matches for export
 	matchIndices          [][]int    // Store match indices for navigation
@@ -30,9 +39,139 @@ type App struct {
 	keybindingsModal *tview.Modal
 	exportPage       *tview.Flex
 	historyPageFlex  *tview.Flex
+	refactorMenu     *tview.List
+	refactorPage     *tview.Flex
+	prettyView       *tview.TextView
+	importForm       *tview.Form
+	importPage       *tview.Flex
+	codeForm         *tview.Form
+	codeView         *tview.TextView
+	codeWarningsView *tview.TextView
+	codePage         *tview.Flex
+	regexCompleter   *completer
+	formatCompleter  *completer
+	synthList        *tview.List
+	synthPage        *tview.Flex
+	referenceView    *ReferenceView
+	tutorialView     *tview.TextView
+	exerciseList     *tview.List
+	exercisePage     *tview.Flex
+	optionsForm      *tview.Form
+	patternList      *tview.List
+	patternPage      *tview.Flex
+	optionsPage      *tview.Flex
 
 	// History and Help state
 	historyFilePath string
+
+	// Test suite checked live against the pattern, nil if none is loaded
+	suite        *TestSuite
+	suiteVisible bool
+
+	// Examples marked in the text for pattern synthesis
+	positiveSpans []Span
+	negativeSpans []Span
+	synthPattern  string // Pattern to restore when the synthesis page is cancelled
+
+	// Pattern being built from a text selection, nil if there is none
+	build *patternBuild
+
+	// Pattern and text saved while a syntax example is tried, nil if none is
+	trial *syntaxTrial
+
+	// Records the pattern is applied to, one at a time
+	recordOpts   RecordOptions
+	matchRecords []int // Index of the record of each match, nil for the whole text
+	records      []Span
+
+	// Filter selecting the records, or lines, the patterns are applied to, nil for none
+	filter      *Filter
+	searchUnits []Span     // The records or lines the patterns are applied to, nil for the whole text
+	filterLines []GrepLine // The lines of the search units
+
+	// Additional patterns highlighted along with the main one
+	patterns       []patternEntry
+	patternResults []patternResult // Of the enabled patterns
+
+	// Stages chained after the main pattern, each searching a group of the previous matches
+	stages      []Stage
+	pipeline    []PipelineMatch // Matches of the main pattern with those of the next stages
+	pipelineErr error
+
+	// Split mode, in which the matches delimit the fields shown in the Matches pane
+	splitOpts  SplitOptions
+	fields     [][]string // Fields of each record or line, or of the whole text
+	fieldUnits []Span     // The record, line or text each row of fields was split from
+
+	// Table mode of the Matches pane, with sortable and filterable columns
+	tableMode bool
+	tableOpts TableOptions
+	table     ResultTable
+	tableRows [][]string // The visible rows, for export
+
+	// Frequency panel counting the distinct values of a group
+	freqVisible bool
+	freqGroup   int // 0 for the whole match
+	freqByValue bool
+
+	// Statistics panel summarising the numbers of a group
+	statsVisible bool
+	statsGroup   int // 0 for the whole match
+
+	// Timeline panel counting the matches per time bucket of a timestamp group
+	timelineVisible bool
+	timelineOpts    TimelineOptions
+	timeline        Timeline
+	timelineErr     error
+	timelineBucket  time.Time // Start of the bucket the matches are restricted to, zero for none
+
+	// Line view of the Highlighted pane, showing only the selected lines
+	lineView  bool
+	grepOpts  GrepOptions
+	grepLines []GrepLine // The lines shown, for export
+
+	// Tutorial being followed, nil outside of the tutorial
+	tutorial *tutorialState
+}
+
+// patternEntry is an additional pattern, highlighted in its own color.
+type patternEntry struct {
+	pattern string
+	enabled bool
+}
+
+// patternResult holds the matches of an additional pattern.
+type patternResult struct {
+	entry   int // Index in the pattern list.
+	indices [][]int
+	matches [][]string
+}
+
+// tutorialState holds the current exercise and the state replaced by the tutorial.
+type tutorialState struct {
+	progress     TutorialProgress
+	progressPath string
+	index        int  // The current exercise.
+	hints        int  // Hints shown for the current exercise.
+	solved       bool // Whether the pattern solves the current exercise.
+	pattern      string
+	text         string
+}
+
+// syntaxTrial holds the state replaced by a syntax reference example.
+type syntaxTrial struct {
+	pattern string
+	text    string
+}
+
+// patternBuild tracks a pattern built from a selection of the text so that it can be
+// generalised and grouped step by step.
+type patternBuild struct {
+	prefix  string // The pattern as it was before the selection was inserted.
+	span    Span   // The selection in the text.
+	groups  []Span // Capture groups, relative to the selection.
+	level   int    // One of the Generalise levels.
+	pattern string // The pattern last written to the input field.
 }
 
 // New creates and initializes a new TUI application.
@@ -48,11 +187,19 @@ func New(initialText string, historyPath string) (*App, error) {
 		textArea:          tview.NewTextArea(),
 		highlightedView:   tview.NewTextView(),
 		matchView:         tview.NewTextView(),
+		matchTable:        tview.NewTable(),
+		matchPages:        tview.NewPages(),
 		helpHintView:      tview.NewTextView(),
+		suiteView:         tview.NewTextView(),
+		freqView:          tview.NewTextView(),
+		statsView:         tview.NewTextView(),
+		timelineList:      tview.NewList(),
+		tutorialView:      tview.NewTextView(),
 		pages:             tview.NewPages(),
 		modalPages:        tview.NewPages(),
-		helpView:          tview.NewTextView(),
 		currentMatchIndex: -1, // No match selected initially
+		tableOpts:         TableOptions{SortColumn: -1},
+		timelineOpts:      TimelineOptions{Group: 1, Bucket: time.Minute},
 		historyFilePath:   historyPath,
 	}
 
@@ -80,6 +227,38 @@ func (a *App) GetRegexInput() string {
 	return a.regexInput.GetText()
 }
 
+// SetRegexInput replaces the pattern in the regex input field and re-evaluates it.
+func (a *App) SetRegexInput(pattern string) {
+	a.regexInput.SetText(pattern)
+	a.updateHighlight()
+}
+
+// SetSuite loads a test suite whose samples are checked live against the pattern, and shows its panel.
+func (a *App) SetSuite(suite TestSuite) {
+	a.suite = &suite
+	if !a.suiteVisible {
+		a.toggleSuiteView()
+	}
+	a.updateHighlight()
+}
+
+// StartTutorial enters the tutorial at the first exercise that is not solved yet.
+func (a *App) StartTutorial() {
+	t := a.loadTutorial()
+	index := 0
+	for index < len(Exercises)-1 && t.progress.Solved[Exercises[index].ID] {
+		index++
+	}
+	a.startExercise(t, index)
+}
+
+// SetLibraries adds user-defined pattern libraries to the F2 library, after the built-in sections.
+func (a *App) SetLibraries(sections []HelpSection) {
+	help := RegexHelpData
+	help.Custom = sections
+	a.libraryView.InitData(help.Entries())
+}
+
 // SaveHistory persists the current history to the file.
 func (a *App) SaveHistory() error {
 	return SaveHistory(a.historyFilePath, History{Patterns: a.historyView.GetItems()})
@@ -1,7 +1,19 @@
 package app
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/rivo/tview"
 )
 
 func TestGenerateExportCustom(t *testing.T) {
@@ -192,3 +204,1512 @@ func TestSearch(t *testing.T) {
 		})
 	}
 }
+
+func TestRefactor(t *testing.T) {
+	testCases := []struct {
+		name     string
+		refactor func(string) (RefactorResult, error)
+		pattern  string
+		expected string
+		groupMap map[int]int
+	}{
+		{
+			name:     "To non-capturing",
+			refactor: RefactorToNonCapturing,
+			pattern:  `(a)(?P<n>b)[(]\(`,
+			expected: `(?:a)(?:b)[(]\(`,
+			groupMap: map[int]int{0: 0},
+		},
+		{
+			name:     "To capturing renumbers",
+			refactor: RefactorToCapturing,
+			pattern:  `(?:x)(a)(?i:y)(b)`,
+			expected: `(x)(a)(?i:y)(b)`,
+			groupMap: map[int]int{0: 0, 1: 2, 2: 3},
+		},
+		{
+			name:     "Name groups",
+			refactor: RefactorNameGroups,
+			pattern:  `(\d+)-(?P<g2>\w)(x)`,
+			expected: `(?P<g1>\d+)-(?P<g2>\w)(?P<g3>x)`,
+			groupMap: map[int]int{0: 0, 1: 1, 2: 2, 3: 3},
+		},
+		{
+			name:     "Single characters to class",
+			refactor: RefactorFactorAlternations,
+			pattern:  `(a|b|-)x`,
+			expected: `([ab\-])x`,
+			groupMap: map[int]int{0: 0, 1: 1},
+		},
+		{
+			name:     "Common prefixes",
+			refactor: RefactorFactorAlternations,
+			pattern:  `foobar|foo|fob`,
+			expected: `fo(?:o(?:bar)?|b)`,
+			groupMap: map[int]int{0: 0},
+		},
+		{
+			name:     "Preferred empty branch stays lazy",
+			refactor: RefactorFactorAlternations,
+			pattern:  `(?:foo|foobar)`,
+			expected: `(?:foo(?:bar)??)`,
+			groupMap: map[int]int{0: 0},
+		},
+		{
+			name:     "Minify",
+			refactor: RefactorMinify,
+			pattern:  `(?:a)[0-9]{1,}(?:bc)x{0,1}(?:de)*[A-Za-z0-9_]{1}`,
+			expected: `a\d+bcx?(?:de)*\w`,
+			groupMap: map[int]int{0: 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.refactor(tc.pattern)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Pattern != tc.expected {
+				t.Errorf("Expected pattern %q, got %q", tc.expected, result.Pattern)
+			}
+			if !reflect.DeepEqual(result.GroupMap, tc.groupMap) {
+				t.Errorf("Expected group map %v, got %v", tc.groupMap, result.GroupMap)
+			}
+		})
+	}
+}
+
+func TestRefactorMinifyMatchesTheSame(t *testing.T) {
+	inputs := []string{"ab", "Ab", "AB", "aB", "a1b", "abab", "ABab", "x", "", "a\nb"}
+	patterns := []string{
+		`(?:(?i)a)b`,
+		`(?:a(?i)b)c`,
+		`(?:(?s).)x`,
+		`(?:(?i)a)+b`,
+		`(?:(?:(?i)a))b`,
+		`(?:a)[0-9]{1,}(?:b){1}`,
+		`(?:ab)*(?:a|b)`,
+	}
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			result, err := RefactorMinify(pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			original := regexp.MustCompile(pattern)
+			minified, err := regexp.Compile(result.Pattern)
+			if err != nil {
+				t.Fatalf("Minified pattern %q does not compile: %v", result.Pattern, err)
+			}
+			for _, input := range inputs {
+				if want, got := original.FindAllString(input, -1), minified.FindAllString(input, -1); !reflect.DeepEqual(want, got) {
+					t.Errorf("%q matches %q in %q, but %q matches %q", pattern, want, input, result.Pattern, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRemapGroupReferences(t *testing.T) {
+	groupMap := map[int]int{0: 0, 1: 2, 2: 3}
+	if got, err := RemapCustomFormat(`$0 $1,$2`, groupMap); err != nil || got != `$0 $2,$3` {
+		t.Errorf("Unexpected custom format: %q, %v", got, err)
+	}
+	if got, err := RemapCustomFormat(`$0 $1,$2;$3 $3`, groupMap); err == nil || got != `$0 $1,$2;$3 $3` {
+		t.Errorf("Expected the format to be kept with an error, got %q, %v", got, err)
+	} else if !strings.Contains(err.Error(), "($3)") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if got := RemapGroupNumbers("1, 2,3", groupMap); got != "2,3" {
+		t.Errorf("Unexpected group numbers: %q", got)
+	}
+}
+
+func TestPrettyPattern(t *testing.T) {
+	got, err := PrettyPattern(`a(b|c)+d`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "a\n(\n  b\n|\n  c\n)+\nd"
+	if got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestTranslateToRE2(t *testing.T) {
+	testCases := []struct {
+		name            string
+		dialect         string
+		input           string
+		expected        string
+		wantUnsupported int
+		wantNotes       int
+	}{
+		{
+			name:      "JavaScript literal with flags",
+			dialect:   DialectJavaScript,
+			input:     `/(?<year>\d{4})-\u00e9\/x/gi`,
+			expected:  `(?i)(?P<year>\d{4})-\x{E9}/x`,
+			wantNotes: 1,
+		},
+		{
+			name:      "Python raw string with flag constants",
+			dialect:   DialectPython,
+			input:     `re.compile(r"(?P<n>[a-z]+)\Z{,3}", re.I | re.M)`,
+			expected:  `(?im)(?P<n>[a-z]+)\z{0,3}`,
+			wantNotes: 0,
+		},
+		{
+			name:            "Java string with lookbehind and possessive quantifier",
+			dialect:         DialectJava,
+			input:           `Pattern.compile("(?<=a)\\d++" + "\\p{Alpha}")`,
+			expected:        `(?<=a)\d++[[:alpha:]]`,
+			wantUnsupported: 2,
+		},
+		{
+			name:            "PCRE delimited verbose pattern with atomic group",
+			dialect:         DialectPCRE,
+			input:           "~a b # comment\n(?>x)\\h~xU",
+			expected:        `(?U)ab(?>x)[\t\p{Zs}]`,
+			wantUnsupported: 1,
+			wantNotes:       1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := TranslateToRE2(tc.dialect, tc.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Pattern != tc.expected {
+				t.Errorf("Expected pattern %q, got %q", tc.expected, result.Pattern)
+			}
+			if len(result.Unsupported) != tc.wantUnsupported {
+				t.Errorf("Expected %d unsupported constructs, got %v", tc.wantUnsupported, result.Unsupported)
+			}
+			if len(result.Notes) != tc.wantNotes {
+				t.Errorf("Expected %d notes, got %v", tc.wantNotes, result.Notes)
+			}
+		})
+	}
+}
+
+func TestGenerateCode(t *testing.T) {
+	testCases := []struct {
+		name         string
+		lang         string
+		pattern      string
+		contains     []string
+		wantWarnings int
+	}{
+		{
+			name:     "Go uses a raw string and named groups",
+			lang:     LangGo,
+			pattern:  `(?P<type>\w+)=(\d+)`,
+			contains: []string{"regexp.MustCompile(`(?P<type>\\w+)=(\\d+)`)", `type_ := m[re.SubexpIndex("type")]`},
+		},
+		{
+			name:    "Go renames groups that clash with its variables",
+			lang:    LangGo,
+			pattern: `(?P<m>a)(?P<re>b)(?P<m_>c)(?P<_>d)(?P<x>e)`,
+			contains: []string{`m__ := m[re.SubexpIndex("m")]`, `re_ := m[re.SubexpIndex("re")]`,
+				`m_ := m[re.SubexpIndex("m_")]`, `__ := m[re.SubexpIndex("_")]`, `x := m[re.SubexpIndex("x")]`,
+				"fmt.Println(m[0], m__, re_, m_, __, x)"},
+		},
+		{
+			name:     "Go quotes patterns with backquotes",
+			lang:     LangGo,
+			pattern:  "`x`",
+			contains: []string{"regexp.MustCompile(\"`x`\")"},
+		},
+		{
+			name:         "Python maps flags and warns about Unicode classes",
+			lang:         LangPython,
+			pattern:      `(?is)'\d+\z`,
+			contains:     []string{`re.compile(r"'\d+\Z", re.IGNORECASE | re.DOTALL)`},
+			wantWarnings: 1,
+		},
+		{
+			name:     "Python rewrites \\x{...} escapes",
+			lang:     LangPython,
+			pattern:  `\x{E9}+[\x{263A}\\x{1F600}]\x{10FFFF}`,
+			contains: []string{`re.compile(r'\xe9+[\u263a\\x{1F600}]\U0010ffff')`},
+		},
+		{
+			name:     "JavaScript literal",
+			lang:     LangJavaScript,
+			pattern:  `(?i)(?P<path>/\p{Greek}+)`,
+			contains: []string{`const re = /(?<path>\/\p{Script=Greek}+)/giu;`, "m.groups.path"},
+		},
+		{
+			name:     "Java string escapes",
+			lang:     LangJava,
+			pattern:  `"[[:alpha:]]"\d`,
+			contains: []string{`Pattern.compile("\"[\\p{Alpha}]\"\\d")`},
+		},
+		{
+			name:         "Java renames group names with underscores",
+			lang:         LangJava,
+			pattern:      `(?P<user_id>\w+) (?P<userId>\d+) (?P<_1>x)`,
+			contains:     []string{`Pattern.compile("(?<userId2>\\w+) (?<userId>\\d+) (?<g1>x)")`, `m.group("userId2") + " " + m.group("userId")`},
+			wantWarnings: 2,
+		},
+		{
+			name:     "Java escapes control characters",
+			lang:     LangJava,
+			pattern:  "\x1b\a\v[\t]é",
+			contains: []string{`Pattern.compile("\u001b\u0007\u000b[\t]é")`},
+		},
+		{
+			name:     "Rust raw string with hashes",
+			lang:     LangRust,
+			pattern:  `"[a-z]"`,
+			contains: []string{`Regex::new(r#""[a-z]""#)`},
+		},
+		{
+			name:         "grep converts to ERE",
+			lang:         LangGrep,
+			pattern:      `(?i)(?:it's)\d+?[\w.]`,
+			contains:     []string{`grep -Eo -i '(it'\''s)[0-9]+[[:alnum:]_.]' file`},
+			wantWarnings: 2,
+		},
+		{
+			name:     "sed substitution",
+			lang:     LangSed,
+			pattern:  `(a)/(b)`,
+			contains: []string{`sed -E 's/(a)\/(b)/\1 \2/g' file`},
+		},
+		{
+			name:         "sed renumbers groups after non-capturing ones",
+			lang:         LangSed,
+			pattern:      `(?:a)(b)(?i:c)(?P<d>d)`,
+			contains:     []string{`sed -E 's/(a)(b)(c)(d)/\2 \4/g' file`},
+			wantWarnings: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			snippet, err := GenerateCode(tc.lang, tc.pattern)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(snippet.Code, want) {
+					t.Errorf("Expected code to contain %q, got:\n%s", want, snippet.Code)
+				}
+			}
+			if len(snippet.Warnings) != tc.wantWarnings {
+				t.Errorf("Expected %d warnings, got %v", tc.wantWarnings, snippet.Warnings)
+			}
+		})
+	}
+}
+
+func TestScanGoSources(t *testing.T) {
+	dir := t.TempDir()
+	src := "package p\n\n" +
+		"import re \"regexp\"\n\n" +
+		"const prefix = `^id-`\n\n" +
+		"var (\n" +
+		"\tvalid   = re.MustCompile(prefix + `\\d+`)\n" +
+		"\tinvalid = re.MustCompile(\"(a\")\n" +
+		"\tdynamic = re.MustCompile(valid.String())\n" +
+		")\n\n" +
+		"func f() bool { ok, _ := re.MatchString(`x(?P<n>y)`, \"xy\"); return ok }\n"
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "p.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ScanGoSources([]string{dir + "/..."})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d: %v", len(results), results)
+	}
+	expected := []struct {
+		line    int
+		pattern string
+		valid   bool
+	}{
+		{8, `^id-\d+`, true},
+		{9, `(a`, false},
+		{13, `x(?P<n>y)`, true},
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Line != want.line || got.Pattern != want.pattern || (got.Err == nil) != want.valid {
+			t.Errorf("Result %d: expected line %d pattern %q valid %v, got %+v", i, want.line, want.pattern, want.valid, got)
+		}
+	}
+}
+
+func TestScanGoSourcesParseError(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a/bad.go":  "package a\n\nimport \"regexp\"\n\nvar x = regexp.MustCompile(`a+`\n",
+		"a/good.go": "package a\n\nimport \"regexp\"\n\nvar y = regexp.MustCompile(`b+`)\n",
+		"b/b.go":    "package b\n\nimport \"regexp\"\n\nvar z = regexp.MustCompile(`c+`)\n",
+	}
+	for name, src := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := ScanGoSources([]string{dir + "/..."})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var patterns []string
+	var parseErrors []ScanResult
+	for _, r := range results {
+		if r.ParseError() {
+			parseErrors = append(parseErrors, r)
+		} else {
+			patterns = append(patterns, r.Pattern)
+		}
+	}
+	if want := []string{"b+", "c+"}; !reflect.DeepEqual(patterns, want) {
+		t.Errorf("Expected the patterns %q of the other files, got %q", want, patterns)
+	}
+	if len(parseErrors) != 1 || filepath.Base(parseErrors[0].File) != "bad.go" || parseErrors[0].Line != 5 || parseErrors[0].Err == nil {
+		t.Fatalf("Expected a parse error at line 5 of bad.go, got %+v", parseErrors)
+	}
+	if out := FormatScanResults(parseErrors); !strings.Contains(out, "bad.go:5: parse error: ") {
+		t.Errorf("Expected the parse error in the output, got %q", out)
+	}
+}
+
+func TestRunSuite(t *testing.T) {
+	suite := TestSuite{
+		Name:    "ids",
+		Pattern: `^id-(?P<n>\d+)$`,
+		Match:   []string{"id-1", "id-x"},
+		NoMatch: []string{"xid-1", "id-2"},
+		Captures: []CaptureCase{
+			{Input: "id-42", Groups: map[string]string{"n": "42", "0": "id-42"}},
+			{Input: "id-42", Groups: map[string]string{"1": "4", "missing": ""}},
+		},
+	}
+
+	results, err := RunSuite(suite, suite.Pattern)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []struct {
+		kind   string
+		passed bool
+		detail string
+	}{
+		{"match", true, ""},
+		{"match", false, "did not match"},
+		{"noMatch", true, ""},
+		{"noMatch", false, `matched "id-2"`},
+		{"capture", true, ""},
+		{"capture", false, `group 1: expected "4", got "42"; group missing does not exist`},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(expected), len(results), results)
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Kind != want.kind || got.Passed != want.passed || got.Detail != want.detail {
+			t.Errorf("Result %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+	if failures := CountFailures(results); failures != 3 {
+		t.Errorf("Expected 3 failures, got %d", failures)
+	}
+
+	if _, err := RunSuite(suite, "(a"); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestSynthesize(t *testing.T) {
+	text := "2024-01-05 ERROR id=42; user=bob\n2024-01-06 INFO id=7; user=alice\nfoo id=x;"
+	span := func(s string) Span {
+		i := strings.Index(text, s)
+		return Span{Start: i, End: i + len(s)}
+	}
+
+	testCases := []struct {
+		name      string
+		positives []Span
+		negatives []Span
+		expected  []string // Leading candidates, in order.
+	}{
+		{
+			name:      "Digits and common prefix",
+			positives: []Span{span("id=42;"), span("id=7;")},
+			expected:  []string{`id=\d+;`, `\w+=\d+;`, `id=\d{1,2};`, `id=\w+;`},
+		},
+		{
+			name:      "Negative example excludes looser candidates",
+			positives: []Span{span("id=42;"), span("id=7;")},
+			negatives: []Span{span("id=x;")},
+			expected:  []string{`id=\d+;`, `\w+=\d+;`, `id=\d{1,2};`, `(?:id=42;|id=7;)`},
+		},
+		{
+			name:      "Letter case is kept",
+			positives: []Span{span("ERROR"), span("INFO")},
+			expected:  []string{`[A-Z]+`, `[A-Z]{4,5}`, `\w{4,5}`, `\w+`},
+		},
+		{
+			name:      "Dates",
+			positives: []Span{span("2024-01-05"), span("2024-01-06")},
+			expected:  []string{`\d+-\d+-\d+`, `\w+-\w+-\w+`, `2024-01-\d+`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			candidates, err := Synthesize(text, tc.positives, tc.negatives)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var patterns []string
+			for _, c := range candidates {
+				patterns = append(patterns, c.Pattern)
+			}
+			if len(patterns) < len(tc.expected) || !reflect.DeepEqual(patterns[:len(tc.expected)], tc.expected) {
+				t.Errorf("Expected candidates to start with %q, got %q", tc.expected, patterns)
+			}
+		})
+	}
+
+	if _, err := Synthesize(text, nil, nil); err == nil {
+		t.Error("Expected an error without positive examples")
+	}
+}
+
+func TestBuildPattern(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		groups   []Span
+		level    int
+		expected string
+	}{
+		{"Literal", "id=42 (v1.2)", nil, GeneraliseLiteral, `id=42 \(v1\.2\)`},
+		{"Digits", "id=42 (v1.2)", nil, GeneraliseDigits, `id=\d+ \(v\d+\.\d+\)`},
+		{"Words", "id=42 (v1.2)", nil, GeneraliseWords, `\w+=\d+ \(\w+\.\d+\)`},
+		{"Whitespace", "id=42  user  bob", nil, GeneraliseWhitespace, `\w+=\d+\s+\w+\s+\w+`},
+		{"Group", "id=42;", []Span{{3, 5}}, GeneraliseDigits, `id=(\d+);`},
+		{"Group splitting a run", "abc123", []Span{{1, 2}}, GeneraliseLiteral, `a(b)c123`},
+		{"Several groups", "a=1,b=2", []Span{{6, 7}, {2, 3}}, GeneraliseDigits, `a=(\d+),b=(\d+)`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := BuildPattern(tc.text, tc.groups, tc.level)
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+			if !regexp.MustCompile("^" + got + "$").MatchString(tc.text) {
+				t.Errorf("Pattern %q does not match %q", got, tc.text)
+			}
+		})
+	}
+}
+
+func TestLoadLibraries(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"team.yaml": "name: Team\npatterns:\n" +
+			"  - title: Ticket ID\n    pattern: '[A-Z]+-\\d+'\n    description: Issue tracker keys\n    tags: [jira, tickets]\n    flags: i\n    sample: fixes abc-123\n",
+		"hosts.json": `{"patterns": [{"title": "Internal host", "pattern": "[a-z0-9-]+\\.corp\\.example"}]}`,
+		"bad.yml":    "patterns:\n  - title: Broken\n    pattern: x\n    flags: ix\n",
+	}
+	var paths []string
+	for _, name := range []string{"team.yaml", "hosts.json", "bad.yml"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	sections, err := LoadLibraries(paths)
+	if err == nil || !strings.Contains(err.Error(), "bad.yml") {
+		t.Errorf("Expected an error for bad.yml, got %v", err)
+	}
+	expected := []HelpSection{
+		{Source: "Team", Items: []HelpItem{{
+			Title: "Ticket ID", Pattern: `[A-Z]+-\d+`, Description: "Issue tracker keys",
+			Tags: []string{"jira", "tickets"}, Flags: "i", Sample: "fixes abc-123",
+		}}},
+		{Source: "hosts", Items: []HelpItem{{Title: "Internal host", Pattern: `[a-z0-9-]+\.corp\.example`}}},
+	}
+	if !reflect.DeepEqual(sections, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, sections)
+	}
+	if got := sections[0].Items[0].FullPattern(); got != `(?i)[A-Z]+-\d+` {
+		t.Errorf("Expected flags to be applied, got %q", got)
+	}
+
+	help := RegexHelpData
+	help.Custom = sections
+	entries := help.Entries()
+	builtIn := len(RegexHelpData.Common) + len(RegexHelpData.Escapes)
+	if len(entries) != builtIn+2 || entries[builtIn].Section != "Team" || entries[builtIn+1].Section != "hosts" {
+		t.Errorf("Expected the libraries after the built-in entries, got %+v", entries[builtIn:])
+	}
+}
+
+func TestSyntaxReference(t *testing.T) {
+	entries := SyntaxReference()
+	syntaxes := map[string]bool{}
+	for _, entry := range entries {
+		syntaxes[entry.Syntax] = true
+		if entry.Example == "" {
+			if entry.Category != SynUnsupported {
+				t.Errorf("%s: missing example", entry.Syntax)
+			}
+			continue
+		}
+		re, err := regexp.Compile(entry.Example)
+		if err != nil {
+			t.Errorf("%s: example %q does not compile: %v", entry.Syntax, entry.Example, err)
+			continue
+		}
+		// Surrogates have no valid text representation.
+		if !re.MatchString(entry.Text) && entry.Syntax != `\p{Cs}` {
+			t.Errorf("%s: example %q does not match %q", entry.Syntax, entry.Example, entry.Text)
+		}
+	}
+	for _, want := range []string{`[[:xdigit:]]`, `\p{Hiragana}`, `\pL`, `\p{Lu}`, `(?P<name>re)`, `\z`} {
+		if !syntaxes[want] {
+			t.Errorf("Expected the reference to contain %s", want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	inserts := func(completions []Completion) []string {
+		var result []string
+		for _, c := range completions {
+			result = append(result, c.Insert)
+		}
+		return result
+	}
+
+	testCases := []struct {
+		name      string
+		before    string
+		pattern   string // Set for format completion.
+		start     int
+		contains  []string
+		exactly   []string
+		hasResult bool
+	}{
+		{name: "Unicode script", before: `a\p{Hira`, start: 1, exactly: []string{`\p{Hiragana}`}, hasResult: true},
+		{name: "Unicode negated, case-insensitive", before: `\P{gre`, start: 0, exactly: []string{`\P{Greek}`}, hasResult: true},
+		{name: "Unicode categories", before: `\p{L`, start: 0, contains: []string{`\p{L}`, `\p{Lu}`, `\p{Latin}`}, hasResult: true},
+		{name: "POSIX class", before: `x[[:al`, start: 2, exactly: []string{`[:alnum:]`, `[:alpha:]`}, hasResult: true},
+		{name: "Negated POSIX class in a class", before: `[a-z[:^dig`, start: 4, exactly: []string{`[:^digit:]`}, hasResult: true},
+		{name: "Group syntax", before: `a(?`, start: 1, contains: []string{`(?:`, `(?P<`, `(?i)`}, hasResult: true},
+		{name: "Flags prefix", before: `(?i`, start: 0, exactly: []string{`(?i)`, `(?i:`}, hasResult: true},
+		{name: "Escaped paren", before: `\(?`},
+		{name: "Nothing to complete", before: `abc`},
+		{name: "Format groups", before: `$`, pattern: `(\d+)-(?P<name>\w+)`, start: 0, exactly: []string{`$0`, `$1`, `$2`}, hasResult: true},
+		{name: "Format partial number", before: `x $1`, pattern: `(a)(b)(c)(d)(e)(f)(g)(h)(i)(j)`, start: 2, exactly: []string{`$1`, `$10`}, hasResult: true},
+		{name: "Format invalid pattern", before: `$`, pattern: `(`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var start int
+			var completions []Completion
+			if tc.pattern != "" {
+				start, completions = CompleteFormat(tc.before, tc.pattern)
+			} else {
+				start, completions = CompletePattern(tc.before)
+			}
+			got := inserts(completions)
+			if !tc.hasResult {
+				if len(got) != 0 {
+					t.Errorf("Expected no completions, got %q", got)
+				}
+				return
+			}
+			if start != tc.start {
+				t.Errorf("Expected start %d, got %d", tc.start, start)
+			}
+			if tc.exactly != nil && !reflect.DeepEqual(got, tc.exactly) {
+				t.Errorf("Expected %q, got %q", tc.exactly, got)
+			}
+			for _, want := range tc.contains {
+				if !slices.Contains(got, want) {
+					t.Errorf("Expected %q in %q", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCompleterCompletesTheEnd(t *testing.T) {
+	field := tview.NewInputField()
+	c := newCompleter(field, CompletePattern)
+	field.SetChangedFunc(c.track)
+	steps := []struct {
+		text string
+		open bool
+	}{
+		{`\p{Gre`, true},  // Typed at the end.
+		{`\p{Gr`, false},  // Deleted.
+		{`x\p{Gr`, false}, // Typed before the end.
+		{`x\p{Gre`, true},
+	}
+	for _, step := range steps {
+		field.SetText(step.text)
+		if got := c.autocomplete(step.text); (len(got) > 0) != step.open {
+			t.Errorf("After %q: expected the drop-down open: %v, got %q", step.text, step.open, got)
+		}
+	}
+	if !c.autocompleted("", 0, tview.AutocompletedEnter) || field.GetText() != `x\p{Greek}` {
+		t.Errorf("Unexpected text after the completion: %q", field.GetText())
+	}
+}
+
+func TestTutorial(t *testing.T) {
+	text, match, noMatch := ParseExercise("a [[cat]] {{concat}} [x] {y}")
+	if text != "a cat concat [x] {y}" {
+		t.Errorf("Unexpected text %q", text)
+	}
+	if !reflect.DeepEqual(match, []Span{{2, 5}}) || !reflect.DeepEqual(noMatch, []Span{{6, 12}}) {
+		t.Errorf("Unexpected spans %v, %v", match, noMatch)
+	}
+
+	// The last hint of every exercise gives a solution.
+	for _, exercise := range Exercises {
+		hint := exercise.Hints[len(exercise.Hints)-1]
+		solution, _, _ := strings.Cut(strings.TrimPrefix(hint, "Try "), " or ")
+		if _, solved, err := CheckExercise(exercise, solution); err != nil || !solved {
+			t.Errorf("%s: %q does not solve it (err: %v)", exercise.ID, solution, err)
+		}
+		if _, solved, _ := CheckExercise(exercise, ""); solved {
+			t.Errorf("%s: solved by an empty pattern", exercise.ID)
+		}
+		if _, solved, _ := CheckExercise(exercise, "(?s).+"); solved {
+			t.Errorf("%s: solved by matching everything", exercise.ID)
+		}
+	}
+	if _, _, err := CheckExercise(Exercises[0], "("); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+
+	path := TutorialProgressPath(filepath.Join(t.TempDir(), "history.json"))
+	progress, err := LoadTutorialProgress(path)
+	if err != nil || len(progress.Solved) != 0 {
+		t.Fatalf("Expected no progress, got %v (err: %v)", progress, err)
+	}
+	if !progress.Unlocked(0) || progress.Unlocked(1) {
+		t.Error("Expected only the first exercise to be unlocked")
+	}
+	progress.Solved[Exercises[0].ID] = true
+	if err := SaveTutorialProgress(path, progress); err != nil {
+		t.Fatal(err)
+	}
+	progress, err = LoadTutorialProgress(path)
+	if err != nil || !progress.Unlocked(1) || progress.Unlocked(2) {
+		t.Errorf("Expected the second exercise to be unlocked, got %v (err: %v)", progress, err)
+	}
+}
+
+func TestGrepLines(t *testing.T) {
+	text := "one\nerror two\nthree\nfour\nfive\nerror six\nseven\n"
+	testCases := []struct {
+		name    string
+		pattern string
+		opts    GrepOptions
+		want    string
+	}{
+		{name: "Matching lines", pattern: `error`, want: "2:error two\n--\n6:error six\n"},
+		{name: "Context", pattern: `error`, opts: GrepOptions{Before: 1, After: 1}, want: "1-one\n2:error two\n3-three\n--\n5-five\n6:error six\n7-seven\n"},
+		{name: "Overlapping context", pattern: `error`, opts: GrepOptions{After: 3}, want: "2:error two\n3-three\n4-four\n5-five\n6:error six\n7-seven\n"},
+		{name: "Invert", pattern: `error`, opts: GrepOptions{Invert: true}, want: "1:one\n--\n3:three\n4:four\n5:five\n--\n7:seven\n"},
+		{name: "Multi-line match", pattern: `(?s)three.four`, want: "3:three\n4:four\n"},
+		{name: "Anchored match", pattern: `(?m)^f`, want: "4:four\n5:five\n"},
+		{name: "Empty match after the final newline", pattern: `(?m)^$`, want: ""},
+		{name: "No match", pattern: `nothing`, want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches := regexp.MustCompile(tc.pattern).FindAllStringIndex(text, -1)
+			got := RenderGrepLines(text, GrepLines(text, matches, tc.opts))
+			if got != tc.want {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSplitRecords(t *testing.T) {
+	log := "2024-01-01 start\n2024-01-02 panic: boom\n  at main.go:1\n  at lib.go:2\n\n2024-01-03 done\n"
+	testCases := []struct {
+		name    string
+		text    string
+		opts    RecordOptions
+		want    []string
+		wantErr bool
+	}{
+		{name: "Whole text", text: "a\nb", opts: RecordOptions{}, want: []string{"a\nb"}},
+		{name: "Lines", text: "a\n\nb\n", opts: RecordOptions{Mode: RecordsLines}, want: []string{"a", "", "b"}},
+		{name: "Blank lines", text: "\na\nb\n\n \n\nc\n", opts: RecordOptions{Mode: RecordsParagraphs}, want: []string{"\na\nb", "c\n"}},
+		{name: "Delimiter", text: "a;b;;c;", opts: RecordOptions{Mode: RecordsDelimiter, Separator: `;`}, want: []string{"a", "b", "", "c"}},
+		{name: "Record start", text: log, opts: RecordOptions{Mode: RecordsStart, Separator: `\d{4}-\d{2}-\d{2}`},
+			want: []string{"2024-01-01 start", "2024-01-02 panic: boom\n  at main.go:1\n  at lib.go:2\n", "2024-01-03 done"}},
+		{name: "Preamble before the first record", text: "header\n1 a\n2 b", opts: RecordOptions{Mode: RecordsStart, Separator: `\d`},
+			want: []string{"header", "1 a", "2 b"}},
+		{name: "Empty separator", opts: RecordOptions{Mode: RecordsDelimiter}, wantErr: true},
+		{name: "Invalid separator", opts: RecordOptions{Mode: RecordsStart, Separator: `(`}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spans, err := SplitRecords(tc.text, tc.opts)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %v, got %v", tc.wantErr, err)
+			}
+			var got []string
+			for _, span := range spans {
+				got = append(got, tc.text[span.Start:span.End])
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSearchRecords(t *testing.T) {
+	text := "id=1 ok\nid=2 fail\nid=3 fail"
+	records, _ := SplitRecords(text, RecordOptions{Mode: RecordsLines})
+
+	// Anchors are relative to each record.
+	indices, matches, recordOf, err := SearchRecords(`^id=(\d) fail$`, text, records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(indices, [][]int{{8, 17}, {18, 27}}) || !reflect.DeepEqual(recordOf, []int{1, 2}) {
+		t.Errorf("Unexpected indices %v and records %v", indices, recordOf)
+	}
+
+	grouped := GroupByRecord(matches, recordOf)
+	data, err := GenerateRecordExportCustom(grouped, "$1")
+	if err != nil || string(data) != "2\n\n3" {
+		t.Errorf("Unexpected custom export %q (err: %v)", data, err)
+	}
+	data, err = GenerateRecordExportJSONGroups(`^id=(\d) fail$`, grouped, "1")
+	want := `{"regex":"^id=(\\d) fail$","records":[{"record":2,"matches":[{"1":"2"}]},{"record":3,"matches":[{"1":"3"}]}]}`
+	var compact bytes.Buffer
+	if err == nil {
+		err = json.Compact(&compact, data)
+	}
+	if compact.String() != want {
+		t.Errorf("Expected %s, got %s (err: %v)", want, compact.String(), err)
+	}
+}
+
+func TestHandleExportRecords(t *testing.T) {
+	testCases := []struct {
+		name    string
+		records RecordOptions
+		want    string
+	}{
+		{name: "Whole text", records: RecordOptions{Mode: RecordsNone}, want: "1\n2\n3"},
+		{name: "Lines", records: RecordOptions{Mode: RecordsLines}, want: "1\n2\n\n3"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := New("id=1 id=2\nid=3", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.recordOpts = tc.records
+			a.SetRegexInput(`id=(\d)`)
+
+			path := filepath.Join(t.TempDir(), "export.txt")
+			a.exportForm.GetFormItemByLabel(LabelExportFormat).(*tview.DropDown).SetCurrentOption(2)
+			a.exportForm.GetFormItemByLabel(LabelCustomFormat).(*tview.InputField).SetText("$1")
+			a.exportForm.GetFormItemByLabel(LabelOutputTarget).(*tview.DropDown).SetCurrentOption(1)
+			a.exportForm.GetFormItemByLabel(LabelFilePath).(*tview.InputField).SetText(path)
+			a.handleExport()
+
+			if data, err := os.ReadFile(path); err != nil || string(data) != tc.want {
+				t.Errorf("Expected %q, got %q (err: %v)", tc.want, data, err)
+			}
+		})
+	}
+}
+
+func TestOverlapSegments(t *testing.T) {
+	testCases := []struct {
+		name      string
+		matchSets [][][]int
+		want      []Segment
+	}{
+		{name: "Disjoint", matchSets: [][][]int{{{0, 2}}, {{3, 5}}}, want: []Segment{{0, 2, []int{0}}, {3, 5, []int{1}}}},
+		{name: "Overlap", matchSets: [][][]int{{{0, 4}}, {{2, 6}}}, want: []Segment{{0, 2, []int{0}}, {2, 4, []int{0, 1}}, {4, 6, []int{1}}}},
+		{name: "Nested", matchSets: [][][]int{{{0, 6}}, {{2, 3}}, {{2, 4}}}, want: []Segment{{0, 2, []int{0}}, {2, 3, []int{0, 1, 2}}, {3, 4, []int{0, 2}}, {4, 6, []int{0}}}},
+		{name: "Adjacent matches of one pattern", matchSets: [][][]int{{{0, 2}, {2, 4}}}, want: []Segment{{0, 2, []int{0}}, {2, 4, []int{0}}}},
+		{name: "Empty matches", matchSets: [][][]int{{{1, 1}}, nil}, want: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := OverlapSegments(tc.matchSets)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expr    string
+		match   []string
+		noMatch []string
+		wantErr bool
+	}{
+		{name: "Precedence", expr: `/ERROR/ AND NOT /healthcheck/ OR /panic/`,
+			match: []string{"ERROR disk", "panic: boom", "panic healthcheck"}, noMatch: []string{"ERROR healthcheck", "INFO"}},
+		{name: "Parentheses", expr: `/ERROR/ and (/db/ or /cache/)`, match: []string{"ERROR db"}, noMatch: []string{"ERROR api", "INFO db"}},
+		{name: "Double negation", expr: `not not /a/`, match: []string{"a"}, noMatch: []string{"b"}},
+		{name: "Flags and escaped slash", expr: `/api\/v1/i`, match: []string{"GET /API/V1/users"}, noMatch: []string{"/api/v2"}},
+		{name: "Keyword needs a boundary", expr: `/a/ ANDY /b/`, wantErr: true},
+		{name: "Unterminated pattern", expr: `/a`, wantErr: true},
+		{name: "Missing parenthesis", expr: `(/a/`, wantErr: true},
+		{name: "Invalid pattern", expr: `/(/`, wantErr: true},
+		{name: "Missing operand", expr: `/a/ AND`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseFilter(tc.expr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %v, got %v", tc.wantErr, err)
+			}
+			for _, s := range tc.match {
+				if !f.Match(s) {
+					t.Errorf("Expected %q to pass", s)
+				}
+			}
+			for _, s := range tc.noMatch {
+				if f.Match(s) {
+					t.Errorf("Expected %q not to pass", s)
+				}
+			}
+		})
+	}
+
+	if f, err := ParseFilter("  "); f != nil || err != nil {
+		t.Errorf("Expected no filter for a blank expression, got %v, %v", f, err)
+	}
+}
+
+func TestRunHeadless(t *testing.T) {
+	text := "ERROR user=a healthcheck\nERROR user=b\nINFO user=c\n2024 panic user=d\n  at main.go\n"
+	testCases := []struct {
+		name string
+		opts HeadlessOptions
+		want string
+	}{
+		{name: "Filtered lines", opts: HeadlessOptions{Filter: `/ERROR/ AND NOT /healthcheck/ OR /panic/`, Format: FormatLines},
+			want: "ERROR user=b\n2024 panic user=d\n"},
+		{name: "Lines with a match", opts: HeadlessOptions{Pattern: `user=[ab]`, Format: FormatLines},
+			want: "ERROR user=a healthcheck\nERROR user=b\n"},
+		{name: "Extraction from filtered lines", opts: HeadlessOptions{Pattern: `user=(\w)`, Filter: `NOT /healthcheck/`, Format: FormatCustom, Custom: "$1"},
+			want: "b\nc\nd"},
+		{name: "Anchors relative to filtered lines", opts: HeadlessOptions{Pattern: `^\w+`, Filter: `/user=[bc]/`, Format: FormatCustom, Custom: "$0"},
+			want: "ERROR\nINFO"},
+		{name: "Filtered records", opts: HeadlessOptions{Pattern: `at (\S+)`, Filter: `/panic/`, Records: RecordOptions{Mode: RecordsStart, Separator: `\d{4}`}, Format: FormatCustom, Custom: "$1"},
+			want: "main.go"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RunHeadless(text, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRunPipeline(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		text    string
+		stages  []Stage
+		want    []PipelineMatch
+		wantErr bool
+	}{
+		{name: "Numbered group", pattern: `\?(\S+)`, text: "/a?id=1&x=2 /b?y=3", stages: []Stage{{Group: "1", Pattern: `(\w+)=(\w+)`}},
+			want: []PipelineMatch{
+				{Groups: []string{"?id=1&x=2", "id=1&x=2"}, Children: []PipelineMatch{
+					{Groups: []string{"id=1", "id", "1"}}, {Groups: []string{"x=2", "x", "2"}}}},
+				{Groups: []string{"?y=3", "y=3"}, Children: []PipelineMatch{{Groups: []string{"y=3", "y", "3"}}}},
+			}},
+		{name: "Named group and whole match", pattern: `(?P<q>\S+)`, text: "a=12", stages: []Stage{{Group: "q", Pattern: `=\d+`}, {Pattern: `\d`}},
+			want: []PipelineMatch{{Groups: []string{"a=12", "a=12"}, Children: []PipelineMatch{
+				{Groups: []string{"=12"}, Children: []PipelineMatch{{Groups: []string{"1"}}, {Groups: []string{"2"}}}}}}}},
+		{name: "No match in the group", pattern: `(\w+)`, text: "abc", stages: []Stage{{Group: "1", Pattern: `\d`}},
+			want: []PipelineMatch{{Groups: []string{"abc", "abc"}, Children: []PipelineMatch{}}}},
+		{name: "Missing group", pattern: `\w+`, text: "abc", stages: []Stage{{Group: "1", Pattern: `\d`}}, wantErr: true},
+		{name: "Missing name", pattern: `(\w+)`, text: "abc", stages: []Stage{{Group: "x", Pattern: `\d`}}, wantErr: true},
+		{name: "Invalid stage", pattern: `\w+`, text: "abc", stages: []Stage{{Pattern: `(`}}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, matches, _ := Search(tc.pattern, tc.text)
+			got, err := RunPipeline(tc.pattern, matches, tc.stages)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	stage, err := ParseStage(`1:(\w+):(\d+)`)
+	if err != nil || stage != (Stage{Group: "1", Pattern: `(\w+):(\d+)`}) {
+		t.Errorf("ParseStage: got %v, %v", stage, err)
+	}
+	if _, err := ParseStage("1"); err == nil {
+		t.Error("ParseStage: expected an error without a pattern")
+	}
+
+	_, _, matches, _ := Search(`(\w+)=(\S+)`, "q=a,b r=c")
+	pipeline, _ := RunPipeline(`(\w+)=(\S+)`, matches, []Stage{{Group: "2", Pattern: `\w`}})
+	got, err := GeneratePipelineExportJSON(`(\w+)=(\S+)`, []Stage{{Group: "2", Pattern: `\w`}}, pipeline, []int{0, 1}, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"regex":"(\\w+)=(\\S+)","stages":[{"group":"2","pattern":"\\w"}],"matches":[` +
+		`{"record":1,"groups":{"1":"q"},"matches":[{"groups":{"0":"a"}},{"groups":{"0":"b"}}]},` +
+		`{"record":2,"groups":{"1":"r"},"matches":[{"groups":{"0":"c"}}]}]}`
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, got); err != nil {
+		t.Fatal(err)
+	}
+	if compact.String() != want {
+		t.Errorf("Expected %s, got %s", want, compact.String())
+	}
+}
+
+func TestSplitFields(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		text    string
+		opts    SplitOptions
+		want    []string
+	}{
+		{name: "Fields", pattern: `\s*,\s*`, text: "a , b,c", want: []string{"a", "b", "c"}},
+		{name: "Limit", pattern: `,`, text: "a,b,c,d", opts: SplitOptions{Limit: 2}, want: []string{"a", "b,c,d"}},
+		{name: "Keep delimiters", pattern: `[,;]`, text: "a,b;c", opts: SplitOptions{KeepDelimiters: true}, want: []string{"a", ",", "b", ";", "c"}},
+		{name: "Keep delimiters with a limit", pattern: `,`, text: "a,b,c", opts: SplitOptions{Limit: 2, KeepDelimiters: true}, want: []string{"a", ",", "b,c"}},
+		{name: "Leading and trailing delimiters", pattern: `,`, text: ",a,", want: []string{"", "a", ""}},
+		{name: "Empty matches", pattern: `x*`, text: "abc", want: []string{"a", "b", "c"}},
+		{name: "Empty text", pattern: `,`, text: "", want: []string{""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SplitFields(tc.pattern, tc.text, []Span{{0, len(tc.text)}}, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got[0], tc.want) {
+				t.Errorf("Expected %q, got %q", tc.want, got[0])
+			}
+			if !tc.opts.KeepDelimiters {
+				n := tc.opts.Limit
+				if n == 0 {
+					n = -1
+				}
+				if split := regexp.MustCompile(tc.pattern).Split(tc.text, n); !reflect.DeepEqual(got[0], split) {
+					t.Errorf("Expected the result of regexp.Split %q, got %q", split, got[0])
+				}
+			}
+		})
+	}
+
+	text := "a,\"b\"\nc,d"
+	units := []Span{{0, 5}, {6, 9}}
+	rows, err := SplitFields(`,`, text, units, SplitOptions{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvData, err := GenerateFieldsExportCSV(rows)
+	if err != nil || string(csvData) != "a,\"\"\"b\"\"\"\nc,d\n" {
+		t.Errorf("Unexpected CSV %q, %v", csvData, err)
+	}
+	jsonData, err := GenerateFieldsExportJSON(`,`, rows, "2")
+	var compact bytes.Buffer
+	if err != nil || json.Compact(&compact, jsonData) != nil || compact.String() != `{"regex":",","fields":[["\"b\""],["d"]]}` {
+		t.Errorf("Unexpected JSON %s, %v", jsonData, err)
+	}
+	custom, err := GenerateExportCustom(FieldMatches(text, units, rows), "$2 <- $0")
+	if err != nil || string(custom) != "\"b\" <- a,\"b\"\nd <- c,d" {
+		t.Errorf("Unexpected custom export %q, %v", custom, err)
+	}
+}
+
+func TestResultTable(t *testing.T) {
+	pattern := `(?P<path>\S+) (\d+)`
+	_, _, matches, _ := Search(pattern, "/b 20 /a 3 /c 100 /a x1")
+	table, err := NewResultTable(pattern, matches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"0", "path", "2"}; !reflect.DeepEqual(table.Headers, want) {
+		t.Errorf("Expected headers %q, got %q", want, table.Headers)
+	}
+
+	column := func(rows [][]string, c int) []string {
+		var cells []string
+		for _, row := range rows {
+			cells = append(cells, row[c])
+		}
+		return cells
+	}
+	testCases := []struct {
+		name   string
+		opts   TableOptions
+		column int
+		want   []string
+	}{
+		{name: "Order of the matches", opts: TableOptions{SortColumn: -1}, column: 2, want: []string{"20", "3", "100"}},
+		{name: "Numbers by value", opts: TableOptions{SortColumn: 2}, column: 2, want: []string{"3", "20", "100"}},
+		{name: "Descending", opts: TableOptions{SortColumn: 2, Descending: true}, column: 2, want: []string{"100", "20", "3"}},
+		{name: "Strings", opts: TableOptions{SortColumn: 1}, column: 1, want: []string{"/a", "/b", "/c"}},
+		{name: "Column filter", opts: TableOptions{SortColumn: -1, Filters: map[int]*regexp.Regexp{1: regexp.MustCompile(`[ab]`)}}, column: 2, want: []string{"20", "3"}},
+		{name: "Unknown columns", opts: TableOptions{SortColumn: 7, Filters: map[int]*regexp.Regexp{7: regexp.MustCompile(`x`)}}, column: 2, want: []string{"20", "3", "100"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := column(table.Visible(tc.opts), tc.column); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	if compareCells("9", "abc") >= 0 || !IsNumber(" 1.5") || IsNumber("1ms") {
+		t.Error("Numbers should sort before text and allow spaces but no units")
+	}
+	data, err := GenerateTableExportCSV(table.Headers, table.Visible(TableOptions{SortColumn: 2, Descending: true})[:1])
+	if err != nil || string(data) != "0,path,2\n/c 100,/c,100\n" {
+		t.Errorf("Unexpected CSV %q, %v", data, err)
+	}
+}
+
+func TestFrequencies(t *testing.T) {
+	_, _, matches, _ := Search(`(\w+)(?: (\d+))?`, "b 1\na 2\nb 1\nc\nb 3")
+	testCases := []struct {
+		name    string
+		group   int
+		byValue bool
+		want    []FreqEntry
+	}{
+		{name: "By count", group: 1, want: []FreqEntry{{"b", 3}, {"a", 1}, {"c", 1}}},
+		{name: "By value", group: 2, byValue: true, want: []FreqEntry{{"", 1}, {"1", 2}, {"2", 1}, {"3", 1}}},
+		{name: "Whole match", group: 0, want: []FreqEntry{{"b 1", 2}, {"a 2", 1}, {"b 3", 1}, {"c", 1}}},
+		{name: "Missing group", group: 3, want: []FreqEntry{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Frequencies(matches, tc.group, tc.byValue); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	want := "3  75.0% ████████████████████ b\n" +
+		"1  25.0% ██████               a\\tb\n"
+	if got := RenderFrequencies([]FreqEntry{{"b", 3}, {"a\tb", 1}}); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	got, err := RunHeadless("x=1 y=2 x=3", HeadlessOptions{Pattern: `(?P<key>\w)=\d`, Format: FormatCounts, CountGroup: "key"})
+	if err != nil || !strings.HasPrefix(string(got), "2  66.7% ") {
+		t.Errorf("Unexpected headless counts %q, %v", got, err)
+	}
+	if _, err := RunHeadless("x", HeadlessOptions{Pattern: `x`, Format: FormatCounts, CountGroup: "1"}); err == nil {
+		t.Error("Expected an error for a missing group")
+	}
+}
+
+func TestNumericStats(t *testing.T) {
+	quantities := []struct {
+		input string
+		value float64
+		unit  string
+		ok    bool
+	}{
+		{"42", 42, UnitNone, true},
+		{" -1.5 ", -1.5, UnitNone, true},
+		{"12ms", 12, UnitTime, true},
+		{"1.5 s", 1500, UnitTime, true},
+		{"1m30s", 90000, UnitTime, true},
+		{"250us", 0.25, UnitTime, true},
+		{"512B", 512, UnitSize, true},
+		{"2 KB", 2048, UnitSize, true},
+		{"1.5MiB", 1.5 * (1 << 20), UnitSize, true},
+		{"2kb", 2048, UnitSize, true},
+		{"fast", 0, UnitNone, false},
+		{"NaN", 0, UnitNone, false},
+	}
+	for _, q := range quantities {
+		value, unit, ok := ParseQuantity(q.input)
+		if ok != q.ok || (ok && (value != q.value || unit != q.unit)) {
+			t.Errorf("ParseQuantity(%q): expected %v %q %v, got %v %q %v", q.input, q.value, q.unit, q.ok, value, unit, ok)
+		}
+	}
+
+	var matches [][]string
+	for i := 1; i <= 100; i++ {
+		matches = append(matches, []string{"", strconv.Itoa(i) + "ms"})
+	}
+	matches = append(matches, []string{"", "2KB"}, []string{"", "n/a"})
+	stats := NumericStats(matches, 1)
+	want := Stats{Count: 100, Skipped: 2, Unit: UnitTime, Sum: 5050, Min: 1, Max: 100, Mean: 50.5, Median: 50.5, P90: 90, P95: 95, P99: 99}
+	histogram := stats.Histogram
+	stats.Histogram = nil
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Expected %+v, got %+v", want, stats)
+	}
+	if len(histogram) != 10 || histogram[0].Count != 10 || histogram[9].Count != 10 || histogram[9].High != 100 {
+		t.Errorf("Unexpected histogram %+v", histogram)
+	}
+
+	single := NumericStats([][]string{{"7"}, {"7"}}, 0)
+	if single.Median != 7 || len(single.Histogram) != 1 || single.Histogram[0].Count != 2 {
+		t.Errorf("Unexpected statistics of equal values %+v", single)
+	}
+	if got := RenderStats(NumericStats(nil, 0)); got != "count   0\n" {
+		t.Errorf("Unexpected statistics without numbers %q", got)
+	}
+
+	got, err := RunHeadless("a=1 b=3", HeadlessOptions{Pattern: `=(?P<n>\d)`, Format: FormatStats, StatGroup: "n"})
+	if err != nil || !strings.Contains(string(got), "median  2\n") {
+		t.Errorf("Unexpected headless statistics %q, %v", got, err)
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	layouts := []struct {
+		values []string
+		want   string
+	}{
+		{[]string{"2024-05-01T10:00:05Z", "2024-05-01T10:00:05.123+02:00"}, time.RFC3339Nano},
+		{[]string{"2024-05-01 10:00:05", "2024-05-01 10:00:05.5"}, "2006-01-02 15:04:05.999999999"},
+		{[]string{"01/May/2024:10:00:05 +0000"}, "02/Jan/2006:15:04:05 -0700"},
+		{[]string{"May  1 10:00:05"}, time.Stamp},
+		{[]string{"1714557605", "1714557605123"}, LayoutUnix},
+	}
+	for _, l := range layouts {
+		if got, err := DetectLayout(l.values); err != nil || got != l.want {
+			t.Errorf("DetectLayout(%q): expected %q, got %q, %v", l.values, l.want, got, err)
+		}
+	}
+	if _, err := DetectLayout([]string{"yesterday"}); err == nil {
+		t.Error("DetectLayout: expected an error")
+	}
+
+	matches := [][]string{{"", "10:00:05"}, {"", "10:00:40"}, {"", "oops"}, {"", "10:02:59"}}
+	timeline, err := BuildTimeline(matches, TimelineOptions{Group: 1, Layout: "15:04:05", Bucket: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var counts []int
+	for _, bucket := range timeline.Buckets {
+		counts = append(counts, bucket.Count)
+	}
+	if !reflect.DeepEqual(counts, []int{2, 0, 1}) || timeline.Skipped != 1 {
+		t.Errorf("Expected counts [2 0 1] with 1 skipped, got %v with %d", counts, timeline.Skipped)
+	}
+	if FormatBucket(timeline.Buckets[2].Start, time.Minute) != "10:02" {
+		t.Errorf("Unexpected bucket %q", FormatBucket(timeline.Buckets[2].Start, time.Minute))
+	}
+	start := timeline.Buckets[0].Start
+	if !timeline.InBucket(1, start, time.Minute) || timeline.InBucket(2, start, time.Minute) || timeline.InBucket(3, start, time.Minute) {
+		t.Error("InBucket: expected only the first two matches in the first bucket")
+	}
+
+	if _, err := BuildTimeline([][]string{{"1"}, {"1714557605"}}, TimelineOptions{Layout: LayoutUnix, Bucket: time.Second}); err == nil {
+		t.Error("Expected an error for too many buckets")
+	}
+
+	timeline, _ = BuildTimeline([][]string{{"2024-05-01T10:00:05Z"}, {"2024-05-01T11:30:00Z"}}, TimelineOptions{Bucket: time.Hour})
+	data, err := GenerateTimelineExportCSV(timeline.Buckets)
+	if want := "start,count\n2024-05-01T10:00:00Z,1\n2024-05-01T11:00:00Z,1\n"; err != nil || string(data) != want {
+		t.Errorf("Expected %q, got %q, %v", want, data, err)
+	}
+}
+
+func TestGenerateExportCSV(t *testing.T) {
+	pattern := `(?P<key>\w+)=(.*)`
+	_, _, matches, _ := Search(pattern, "a=1,2\nb=say \"hi\"\nc=x\ty")
+	testCases := []struct {
+		name   string
+		groups string
+		header HeaderMode
+		comma  rune
+		want   string
+	}{
+		{name: "Quoting", header: HeaderNames, comma: ',',
+			want: "0,key,2\n\"a=1,2\",a,\"1,2\"\n\"b=say \"\"hi\"\"\",b,\"say \"\"hi\"\"\"\nc=x\ty,c,x\ty\n"},
+		{name: "TSV", groups: "1,2", header: HeaderNumbers, comma: '\t',
+			want: "1\t2\na\t1,2\nb\t\"say \"\"hi\"\"\"\nc\t\"x\ty\"\n"},
+		{name: "Subset without header", groups: "2,1", header: HeaderNone, comma: ',',
+			want: "\"1,2\",a\n\"say \"\"hi\"\"\",b\nx\ty,c\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := GenerateExportCSV(pattern, matches, tc.groups, tc.header, tc.comma)
+			if err != nil || string(got) != tc.want {
+				t.Errorf("Expected %q, got %q, %v", tc.want, got, err)
+			}
+		})
+	}
+
+	re := regexp.MustCompile(pattern)
+	if headers := groupHeaders(re); !reflect.DeepEqual(headers, []string{"0", "key", "2"}) || re.SubexpNames()[0] != "" {
+		t.Errorf("Expected the headers without changing the names of the pattern, got %q and %q", headers, re.SubexpNames())
+	}
+	if _, err := GenerateExportCSV(pattern, matches, "3", HeaderNames, ','); err == nil {
+		t.Error("Expected an error for a missing group")
+	}
+	got, err := RunHeadless("x=\"1\"", HeadlessOptions{Pattern: pattern, Format: FormatTSV, Groups: "key"})
+	if err == nil {
+		t.Errorf("Expected an error for a group name, got %q", got)
+	}
+	got, err = RunHeadless("x=1\ny=2", HeadlessOptions{Pattern: pattern, Format: FormatCSV, Groups: "1,2", Header: HeaderNames})
+	if err != nil || string(got) != "key,2\nx,1\ny,2\n" {
+		t.Errorf("Unexpected headless CSV %q, %v", got, err)
+	}
+}
+
+func TestGenerateExportNDJSON(t *testing.T) {
+	pattern := `user=(?P<name>\w+)(?: id=(\d+))?`
+	text := "héllo user=bob id=7\r\nnone\nuser=al"
+	want := []NDJSONMatch{
+		{Index: 0, Start: 7, End: 20, Line: 1, Col: 7, Groups: map[string]string{"0": "user=bob id=7", "1": "bob", "name": "bob", "2": "7"}},
+		{Index: 1, Start: 27, End: 34, Line: 3, Col: 1, Groups: map[string]string{"0": "user=al", "1": "al", "name": "al", "2": ""}},
+	}
+	decode := func(data []byte) []NDJSONMatch {
+		var matches []NDJSONMatch
+		for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			var match NDJSONMatch
+			if err := json.Unmarshal([]byte(line), &match); err != nil {
+				t.Fatalf("Invalid line %q: %v", line, err)
+			}
+			matches = append(matches, match)
+		}
+		return matches
+	}
+
+	_, indices, matches, _ := Search(pattern, text)
+	data, err := GenerateExportNDJSON(pattern, text, indices, matches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decode(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	data, err = RunHeadless(text, HeadlessOptions{Pattern: pattern, Format: FormatNDJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decode(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the same matches streamed, got %v", got)
+	}
+
+	data, err = RunHeadless(text, HeadlessOptions{Pattern: pattern, Format: FormatNDJSON, Filter: "/id/"})
+	if got := decode(data); err != nil || len(got) != 1 || got[0].Line != 1 {
+		t.Errorf("Expected the match of the first line, got %v, %v", got, err)
+	}
+	if _, err := RunHeadless(text, HeadlessOptions{Pattern: pattern, Format: FormatNDJSON, Records: RecordOptions{Mode: RecordsLines}}); err == nil {
+		t.Error("Expected an error for records")
+	}
+}
+
+func TestGenerateExportTemplate(t *testing.T) {
+	pattern := `user=(?P<name>\w+)(?: id=(\d+))?`
+	text := "héllo user=bob id=7\nuser=al <x>"
+	_, indices, matches, _ := Search(pattern, text)
+	testCases := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{name: "Fields", template: `{{.Index}} {{.Line}}:{{.Col}} {{index .Groups 0}}`, want: "0 1:7 user=bob id=7\n1 2:1 user=al\n"},
+		{name: "Helpers", template: `{{.Named.name | upper}} {{default "none" (index .Groups 2)}} {{replace "b" "B" .Named.name | printf "%q"}} {{trim " x " | lower}}`,
+			want: "BOB 7 \"BoB\" x\nAL none \"al\" x\n"},
+		{name: "JSON", template: `{{json .Named}}`, want: "{\"name\":\"bob\"}\n{\"name\":\"al\"}\n"},
+		{name: "Conditionals leave matches out", template: `{{if index .Groups 2}}{{.Named.name}}{{end}}`, want: "bob\n"},
+		{name: "Header and footer", template: `{{define "header"}}{{.Regex}}{{end}}{{define "footer"}}{{len .Matches}} users{{end}}{{.Named.name}}`,
+			want: pattern + "\nbob\nal\n2 users\n"},
+		{name: "Unknown name", template: `{{.Named.id}}`, wantErr: true},
+		{name: "Syntax error", template: `{{.Line`, wantErr: true},
+		{name: "Empty", template: ``, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := GenerateExportTemplate(pattern, text, indices, matches, tc.template)
+			if (err != nil) != tc.wantErr || string(got) != tc.want {
+				t.Errorf("Expected %q (error %v), got %q, %v", tc.want, tc.wantErr, got, err)
+			}
+		})
+	}
+
+	got, err := RunHeadless(text, HeadlessOptions{Pattern: pattern, Format: FormatTemplate, Template: `{{.Line}}`, Filter: "/al/"})
+	if err != nil || string(got) != "2\n" {
+		t.Errorf("Expected the line of the filtered match, got %q, %v", got, err)
+	}
+}
+
+func TestLibraryViewFilter(t *testing.T) {
+	entries := []LibraryEntry{
+		{Section: "Common", HelpItem: HelpItem{Title: "Email", Pattern: `\w+@\w+`, Tags: []string{"web"}}},
+		{Section: "Common", HelpItem: HelpItem{Title: "[a-z] words", Pattern: `[a-z]+`, Description: "Lowercase words"}},
+		{Section: "[red]Mine", HelpItem: HelpItem{Title: "Number", Pattern: `\d+`}},
+	}
+	lv := NewLibraryView(func(p tview.Primitive) {})
+	lv.InitData(entries)
+
+	testCases := []struct {
+		name   string
+		search string
+		want   []string
+	}{
+		{name: "Everything", search: "", want: []string{"Email", "[a-z] words", "Number"}},
+		{name: "Title, case-insensitive", search: "EMAIL", want: []string{"Email"}},
+		{name: "Tag", search: "web", want: []string{"Email"}},
+		{name: "Description", search: "lowercase", want: []string{"[a-z] words"}},
+		{name: "Pattern", search: `\d`, want: []string{"Number"}},
+		{name: "Section", search: "mine", want: []string{"Number"}},
+		{name: "Nothing", search: "xyz", want: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lv.filterEntries(tc.search)
+			var titles []string
+			for i, entry := range lv.filtered {
+				titles = append(titles, entry.Title)
+				// Titles and sections from user libraries are shown literally, not as color tags.
+				if got := lv.table.GetCell(i+1, 2).Text; got != tview.Escape(entry.Title) {
+					t.Errorf("Expected the escaped title %q, got %q", tview.Escape(entry.Title), got)
+				}
+				if got := lv.table.GetCell(i+1, 1).Text; got != tview.Escape(entry.Section) {
+					t.Errorf("Expected the escaped section %q, got %q", tview.Escape(entry.Section), got)
+				}
+			}
+			if !reflect.DeepEqual(titles, tc.want) {
+				t.Errorf("Expected %q, got %q", tc.want, titles)
+			}
+		})
+	}
+}
+
+func TestLibraryViewCompileMarker(t *testing.T) {
+	testCases := []struct {
+		name   string
+		item   HelpItem
+		marker string
+	}{
+		{name: "Valid", item: HelpItem{Title: "Digits", Pattern: `\d+`}, marker: "✓"},
+		{name: "Valid with flags", item: HelpItem{Title: "Lines", Pattern: `^x$`, Flags: "m"}, marker: "✓"},
+		{name: "Lookahead", item: HelpItem{Title: "Lookahead", Pattern: `a(?=b)`}, marker: "✗"},
+		{name: "Unknown flag", item: HelpItem{Title: "Flag", Pattern: `x`, Flags: "x"}, marker: "✗"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lv := NewLibraryView(func(p tview.Primitive) {})
+			lv.InitData([]LibraryEntry{{Section: "Test", HelpItem: tc.item}})
+			if got := lv.table.GetCell(1, 0).Text; got != tc.marker {
+				t.Errorf("Expected %s, got %s", tc.marker, got)
+			}
+		})
+	}
+}
+
+func TestLoadSuiteFile(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "Valid", content: `{"suites": [{"name": "ids", "pattern": "\\d+"}]}`},
+		{name: "No suites", content: `{"suites": []}`, wantErr: "no suites defined"},
+		{name: "Empty pattern", content: `{"suites": [{"name": "ids", "pattern": "\\d+"}, {"name": "words", "pattern": ""}]}`, wantErr: `suite "words" has no pattern`},
+		{name: "Missing pattern", content: `{"suites": [{"match": ["a"]}]}`, wantErr: "suite #1 has no pattern"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "suite.json")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadSuiteFile(path)
+			if tc.wantErr == "" && err != nil || tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("Expected error %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSuiteFileSuite(t *testing.T) {
+	file := SuiteFile{Suites: []TestSuite{{Name: "ids", Pattern: `\d+`}, {Name: "words", Pattern: `\w+`}}}
+	testCases := []struct {
+		name    string
+		suite   string
+		want    string
+		wantErr bool
+	}{
+		{name: "First by default", suite: "", want: "ids"},
+		{name: "By name", suite: "words", want: "words"},
+		{name: "Unknown name", suite: "emails", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := file.Suite(tc.suite)
+			if (err != nil) != tc.wantErr || got.Name != tc.want {
+				t.Errorf("Expected suite %q (error %v), got %q, %v", tc.want, tc.wantErr, got.Name, err)
+			}
+		})
+	}
+	if _, err := file.Suite("emails"); err == nil || !strings.Contains(err.Error(), `"ids", "words"`) {
+		t.Errorf("Expected the error to list the suites, got %v", err)
+	}
+}
@@ -8,19 +8,66 @@ const (
 	HistoryPage         = "history_page"
 	ExportPage          = "export"
 	ResultPage          = "result"
+	RefactorPage        = "refactor"
+	PrettyPage          = "pretty"
+	ImportPage          = "import"
+	CodePage            = "code"
+	SynthPage           = "synth"
+	ReferencePage       = "reference"
+	TutorialPage        = "tutorial"
+	OptionsPage         = "options"
+	PatternsPage        = "patterns"
+	PipelinePage        = "pipeline"
+	TableFilterPage     = "table_filter"
+	TimelineLayoutPage  = "timeline_layout"
+	MatchListPage       = "match_list"
+	MatchTablePage      = "match_table"
 )
 
 // Widget Titles
 const (
-	TitleRegex         = "Regular Expression"
-	TitleText          = "Text Input"
-	TitleHighlighted   = "Highlighted"
-	TitleMatches       = "Matches"
-	TitleHelp          = "Help"
-	TitleExportOptions = "Export Matches"
-	TitleSuccess       = "Success"
-	TitleError         = "Error"
-	TitleMatchesFormat = "Matches (%d)"
+	TitleRegex                = "Regular Expression"
+	TitleText                 = "Text Input"
+	TitleHighlighted          = "Highlighted"
+	TitleMatches              = "Matches"
+	TitleHelp                 = "Help"
+	TitleExportOptions        = "Export Matches"
+	TitleSuccess              = "Success"
+	TitleError                = "Error"
+	TitleMatchesFormat        = "Matches (%d)"
+	TitleMatchesRecordsFormat = "Matches (%d in %d of %d records)"
+	TitleFieldsFormat         = "Fields (%d)"
+	TitleTableFormat          = "Table (%d of %d rows; s sort, f filter, F clear, t list)"
+	TitleTableFilterFormat    = "Filter Column %s (regex, empty to clear)"
+	TitleFreqFormat           = "Counts of %s (%d distinct)"
+	TitleStatsFormat          = "Statistics of %s"
+	TitleTimelineFormat       = "Timeline of %s per %s"
+	TitleTimelineSkipped      = " (%d skipped)"
+	TitleTimelineLayout       = "Timestamp Layout (Go layout, e.g. 2006-01-02 15:04:05, or unix; empty to detect)"
+	TitleBucketFormat         = "Highlighted (%s: %d matches)"
+	TitleRefactor             = "Refactor Pattern (Enter to apply, Esc to close)"
+	TitlePretty               = "Pattern Structure (Esc to close)"
+	TitleImport               = "Import Pattern from Another Dialect"
+	TitleCode                 = "Generate Code"
+	TitleCodeWarnings         = "Dialect Differences"
+	TitleScan                 = "Regex Literals (Enter to open, Esc to quit)"
+	TitleTests                = "Tests"
+	TitleTestsFormat          = "Tests (%d/%d passed)"
+	TitleTextExamples         = "Text Input (+%d / -%d examples)"
+	TitleLibrary              = "Regex Library (Enter insert, r replace, Esc close)"
+	TitleLibraryPreview       = "Preview"
+	TitleLibraryPreviewFormat = "Preview (%d matches)"
+	TitleReference            = "RE2 Syntax Reference (Enter or t to try it, Esc to close)"
+	TitleReferenceExample     = "Example"
+	TitleSynth                = "Candidate Patterns (Enter to keep, Esc to restore)"
+	TitleLinesFormat          = "Highlighted (%d lines, -B%d -A%d%s)"
+	TitleLinesInverted        = ", inverted"
+	TitleFilterFormat         = "Highlighted (filter: %d of %d %s)"
+	TitlePatterns             = "Patterns (Space enable/disable, d delete, Enter swap with main, Esc close)"
+	TitleOptions              = "Text Processing"
+	TitlePipeline             = "Pipeline (input group: number, name, or empty for the whole match)"
+	TitleExercises            = "Tutorial Exercises (Enter to start, Esc to close)"
+	TitleTutorialFormat       = "Tutorial (%d/%d solved)"
 )
 
 // Form Labels & Button Text
@@ -28,9 +75,30 @@ const (
 	LabelRegex        = "Regex: "
 	LabelExportFormat = "Export Format"
 	LabelCustomFormat = "Custom Format String"
+	LabelTemplate     = "Go Template"
 	LabelGroupNumbers = "Group Numbers (comma-separated)"
+	LabelHeaderRow    = "CSV/TSV Header Row"
 	LabelOutputTarget = "Export Destination"
 	LabelFilePath     = "File Path"
+	LabelDialect      = "Dialect"
+	LabelForeignRegex = "Pattern"
+	ButtonTranslate   = "Translate"
+	LabelRecords      = "Records"
+	LabelSeparator    = "Separator Regex"
+	LabelFilter       = "Filter"
+	ButtonApply       = "Apply"
+	ButtonPipeline    = "Pipeline"
+	LabelPatternUse   = "Pattern"
+	LabelSplitLimit   = "Split Limit (0: none)"
+	LabelLayout       = "Layout: "
+	LabelStageGroup   = "Stage %d Input Group"
+	LabelStagePattern = "Stage %d Pattern"
+	ButtonAddStage    = "Add Stage"
+	ButtonRemoveStage = "Remove Stage"
+	LabelLanguage     = "Language"
+	ButtonCode        = "Code"
+	ButtonCopy        = "Copy"
+	ButtonClose       = "Close"
 	ButtonExport      = "Export"
 	ButtonCancel      = "Cancel"
 	ButtonOK          = "OK"
@@ -38,9 +106,51 @@ const (
 
 // Export Options
 const (
-	OptJsonAll    = "JSON (all content)"
-	OptJsonGroups = "JSON (specific groups)"
-	OptCustom     = "Custom format"
+	OptJsonAll     = "JSON (all content)"
+	OptJsonGroups  = "JSON (specific groups)"
+	OptCustom      = "Custom format"
+	OptCsv         = "CSV (groups)"
+	OptTsv         = "TSV (groups)"
+	OptLines       = "Lines (line view)"
+	OptFieldsCsv   = "CSV (split fields)"
+	OptTableCsv    = "CSV (table view)"
+	OptFrequencies = "Frequencies (uniq -c)"
+	OptStats       = "Statistics (numbers)"
+	OptTimelineCsv = "CSV (timeline)"
+	OptNdjson      = "NDJSON (one match per line)"
+	OptTemplate    = "Go template"
+)
+
+// DefaultTemplate is the initial template of the OptTemplate export.
+const DefaultTemplate = `{{.Line}}:{{.Col}} {{index .Groups 0 | printf "%q"}}`
+
+// Record Modes, in the order of RecordMode
+var RecordModeOptions = []string{
+	"Whole text",
+	"One per line",
+	"Separated by blank lines",
+	"Separated by the separator regex",
+	"Starting at lines that begin with the separator regex",
+}
+
+// Header rows of the CSV and TSV exports, in the order of HeaderMode
+var HeaderRowOptions = []string{"Group names", "Group numbers", "None"}
+
+// Uses of the pattern: finding matches, or splitting at them with or without the delimiters
+var PatternUseOptions = []string{
+	"Find matches",
+	"Split at matches",
+	"Split at matches, keeping them as fields",
+}
+
+// Refactor Actions
+const (
+	ActNonCapturing = "Capturing groups -> non-capturing"
+	ActCapturing    = "Non-capturing groups -> capturing"
+	ActNameGroups   = "Number groups into named groups"
+	ActAlternations = "Literal alternations -> classes / prefixes"
+	ActMinify       = "Minify"
+	ActPretty       = "Pretty-print"
 )
 
 // Output Targets
@@ -54,16 +164,78 @@ const (
 	HelpKeybindings = `[yellow]KEYBINDINGS:
 
 [green]F1[white]:           Show this help modal
-[green]F2[white]:           Show regex pattern help
+[green]F2[white]:           Browse the regex pattern library
+[green]F4[white]:           Refactor the current pattern
+[green]F5[white]:           Import a PCRE, JavaScript, Python or Java pattern
+[green]F6[white]:           Propose patterns from the marked examples
+[green]F7[white]:           Toggle the test suite panel (--suite FILE)
+[green]F8 / F9[white]:      Mark the selected text as a positive / negative example
+                (without a selection: clear the examples)
+[green]F10[white]:          Show the next hint of the tutorial exercise
+[green]F11[white]:          Choose a tutorial exercise (Enter moves on once it is solved)
+[green]F12[white]:          Search the RE2 syntax reference
 [green]Ctrl+E[white]:       Show export options
-[green]Tab / Shift+Tab[white]: Cycle focus between windows
+[green]Ctrl+G[white]:       Generate code for the current pattern
+[green]Ctrl+N[white]:       Add the pattern to the highlighted patterns, each in its own color
+[green]Ctrl+P[white]:       Enable, disable or delete the highlighted patterns
+[green]Ctrl+S[white]:       Split the text into records the pattern is applied to, and
+                filter them, e.g. /ERROR/ AND NOT /healthcheck/i OR /panic/;
+                split at the matches; Pipeline chains patterns over the groups
+                of each match
+[green]Ctrl+T[white]:       Append the selected text to the pattern, escaped
+[green]Ctrl+R[white]:       Generalise it: literal, \d+, \w+, \s+
+[green]Ctrl+O[white]:       Wrap the selected part of it in a capture group
+[green]Tab / Shift+Tab[white]: Cycle focus between windows, or move through completions
+                (offered after \p{, [[:, (? in the pattern and $ in the custom format)
 [green]Ctrl+C / Ctrl+D[white]: Quit the application
 [green]ESC[white]:          Close help or modals`
 
 	HelpScrolling = `[yellow]SCROLLING (in 'Highlighted' and 'Matches' windows):
 
 - [green]Arrow Keys[white]: Scroll up, down, left, right
-- [green]h, j, k, l[white]:  Vim-style scrolling (left, down, up, right)`
+- [green]h, j, k, l[white]:  Vim-style scrolling (left, down, up, right)
+- [green]n / N[white]:       Jump to the next / previous match
+
+[yellow]LINE VIEW (in the 'Highlighted' window):
+
+- [green]v[white]:           Show only the lines with matches, numbered like grep -n
+- [green]i[white]:           Invert: show only the lines without matches
+- [green]+ / -[white]:       More / fewer context lines before and after
+- [green]b / B, a / A[white]: More / fewer context lines before, after
+- Export the lines shown with the '` + OptLines + `' format (Ctrl+E)
+
+[yellow]TABLE (in the 'Matches' window):
+
+- [green]t[white]:           Switch between the list and a table with a column per group
+- [green]s[white]:           Sort by the selected column: ascending, descending, unsorted
+- [green]f / F[white]:       Filter the selected column with a regex / clear the filters
+- Export the visible rows with the '` + OptTableCsv + `' format (Ctrl+E)
+
+[yellow]COUNTS (press c in the 'Matches' window):
+
+- [green]g[white]:           Count the next group (0 is the whole match)
+- [green]o[white]:           Sort by count or by value
+- Export the counts with the '` + OptFrequencies + `' format (Ctrl+E)
+
+[yellow]STATISTICS (press m in the 'Matches' window):
+
+- Count, sum, min, max, mean, median, p90, p95, p99 and a histogram of the
+  numbers of a group, which may have units: 12ms, 1.5s, 1m30s, 512B, 2KB, 1.5MiB
+- [green]g[white]:           Summarise the next group (0 is the whole match)
+- Export the statistics with the '` + OptStats + `' format (Ctrl+E)
+
+[yellow]TIMELINE (press w in the 'Matches' window):
+
+- Matches per second, minute or hour of a timestamp group, whose layout is
+  detected (RFC 3339, Common Log Format, syslog, Unix seconds...) or given
+- [green]Enter[white]:       Show only the matches of the bucket, again to show all
+- [green]g / b / y[white]:   Next group / bucket size / set the layout
+- [green]x / w[white]:       Show all the matches / close the timeline
+- Export the buckets with the '` + OptTimelineCsv + `' format (Ctrl+E)`
+
+	HintTrying = "Trying a syntax example | Esc or F12 restores your pattern and text"
+
+	HintTutorial = "Tutorial | F10 Hint | F11 Exercises | Enter Next once solved | Esc Leave the tutorial"
 
-	HintHelp = "F1 Helps | F2 Regex Help | F3 History | Ctrl+E Export | Ctrl+C Quit"
+	HintHelp = "F1 Helps | F2 Regex Help | F3 History | F4 Refactor | F5 Import | Ctrl+E Export | Ctrl+C Quit"
 )
@@ -10,43 +10,52 @@ func (a *App) setupEventHandlers() {
 	// --- Regex Input Field specific handlers ---
 	a.regexInput.SetChangedFunc(func(text string) {
 		// Reset history navigation on manual input
+		a.regexCompleter.track(text)
 		a.updateHighlight()
 	})
 
 	a.regexInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if a.regexCompleter.isOpen() {
+			return a.regexCompleter.handleKey(event)
+		}
 		switch event.Key() {
 		case tcell.KeyEnter:
-			a.updateHighlight()
+			if !a.nextExercise() {
+				a.updateHighlight()
+			}
 			return nil
 		}
 		return event
 	})
 
 	a.textArea.SetChangedFunc(func() {
+		a.clearExamples()
+		a.build = nil
 		a.updateHighlight()
 	})
 
 	// Set input capture for views that have special navigation
 	a.highlightedView.SetInputCapture(a.handleViewNavigation)
 	a.matchView.SetInputCapture(a.handleViewNavigation)
+	a.matchTable.SetInputCapture(a.handleTableKey)
+	a.freqView.SetInputCapture(a.handleFreqKey)
+	a.statsView.SetInputCapture(a.handleStatsKey)
+	a.timelineList.SetInputCapture(a.handleTimelineKey)
+	a.timelineList.SetSelectedFunc(func(index int, _, _ string, _ rune) { a.selectBucket(index) })
 
 	// Set global input capture for app-wide events
 	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		// If a modal page is currently displayed, don't allow main page shortcuts.
 		// The modals have their own input handling (or it's handled globally here).
-		if a.modalPages.HasPage(ExportPage) || a.modalPages.HasPage(HistoryPage) || a.modalPages.HasPage(RegexHelpPage) || a.modalPages.HasPage(KeybindingsHelpPage) {
+		if page := a.topModalPage(); page != "" {
 			// Check for modal-closing keys
 			switch event.Key() {
 			case tcell.KeyEsc:
-				if a.modalPages.HasPage(ExportPage) {
-					a.modalPages.RemovePage(ExportPage)
-				} else if a.modalPages.HasPage(HistoryPage) {
-					a.modalPages.RemovePage(HistoryPage)
-				} else if a.modalPages.HasPage(RegexHelpPage) {
-					a.modalPages.RemovePage(RegexHelpPage)
-				} else if a.modalPages.HasPage(KeybindingsHelpPage) {
-					a.modalPages.RemovePage(KeybindingsHelpPage)
+				if page == SynthPage {
+					a.closeSynthPage(false)
+					return nil
 				}
+				a.modalPages.RemovePage(page)
 				a.app.SetFocus(a.regexInput)
 				return nil
 			case tcell.KeyF1:
@@ -67,6 +76,30 @@ func (a *App) setupEventHandlers() {
 					a.app.SetFocus(a.regexInput)
 					return nil
 				}
+			case tcell.KeyF4:
+				if a.modalPages.HasPage(RefactorPage) {
+					a.modalPages.RemovePage(RefactorPage)
+					a.app.SetFocus(a.regexInput)
+					return nil
+				}
+			case tcell.KeyF5:
+				if a.modalPages.HasPage(ImportPage) {
+					a.modalPages.RemovePage(ImportPage)
+					a.app.SetFocus(a.regexInput)
+					return nil
+				}
+			case tcell.KeyF11:
+				if a.modalPages.HasPage(TutorialPage) {
+					a.modalPages.RemovePage(TutorialPage)
+					a.app.SetFocus(a.regexInput)
+					return nil
+				}
+			case tcell.KeyF12:
+				if a.modalPages.HasPage(ReferencePage) {
+					a.modalPages.RemovePage(ReferencePage)
+					a.app.SetFocus(a.regexInput)
+					return nil
+				}
 			}
 			// If not a closing key, let the modal handle it
 			return event
@@ -75,28 +108,106 @@ func (a *App) setupEventHandlers() {
 		// If no modal page is active, handle global application shortcuts.
 		switch event.Key() {
 		case tcell.KeyCtrlC, tcell.KeyCtrlD:
+			if a.trial != nil {
+				a.endSyntaxTrial(false)
+			}
+			if a.tutorial != nil {
+				a.leaveTutorial()
+			}
 			a.app.Stop()
 			return nil
+		case tcell.KeyEsc:
+			if a.trial != nil {
+				a.endSyntaxTrial(true)
+				return nil
+			}
+			if a.tutorial != nil {
+				a.leaveTutorial()
+				return nil
+			}
+		case tcell.KeyF10: // Show the Next Tutorial Hint
+			a.showHint()
+			return nil
+		case tcell.KeyF11: // Show Tutorial Exercises
+			a.showExercises()
+			return nil
+		case tcell.KeyF12: // Show Syntax Reference, or return to it from an example
+			if a.trial != nil {
+				a.endSyntaxTrial(true)
+				return nil
+			}
+			a.modalPages.AddPage(ReferencePage, a.referenceView, true, true)
+			a.app.SetFocus(a.referenceView)
+			return nil
 		case tcell.KeyF1: // Show Keybindings Help
 			a.modalPages.AddPage(KeybindingsHelpPage, a.keybindingsModal, true, true)
 			a.app.SetFocus(a.keybindingsModal)
 			return nil
 		case tcell.KeyF2: // Show Regex Help
-			a.modalPages.AddPage(RegexHelpPage, a.helpView, true, true)
-			a.app.SetFocus(a.helpView)
+			a.libraryView.SetText(a.textArea.GetText())
+			a.modalPages.AddPage(RegexHelpPage, a.libraryView, true, true)
+			a.app.SetFocus(a.libraryView)
 			return nil
 		case tcell.KeyF3: // Show History Page
 			a.modalPages.AddPage(HistoryPage, a.historyPageFlex, true, true)
 			a.app.SetFocus(a.historyView) // Set focus to the history view
 			return nil
+		case tcell.KeyF4: // Show Refactor Menu
+			a.modalPages.AddPage(RefactorPage, a.refactorPage, true, true)
+			a.app.SetFocus(a.refactorMenu)
+			return nil
+		case tcell.KeyF5: // Show Import Form
+			a.modalPages.AddPage(ImportPage, a.importPage, true, true)
+			a.app.SetFocus(a.importForm)
+			return nil
+		case tcell.KeyF6: // Show Synthesized Patterns
+			a.showSynthPage()
+			return nil
+		case tcell.KeyF8: // Mark Positive Example
+			a.markExample(true)
+			return nil
+		case tcell.KeyF9: // Mark Negative Example
+			a.markExample(false)
+			return nil
+		case tcell.KeyF7: // Toggle Test Suite Panel
+			a.toggleSuiteView()
+			return nil
 		case tcell.KeyCtrlE: // Show Export Options
 			a.modalPages.AddPage(ExportPage, a.exportPage, true, true)
 			a.app.SetFocus(a.exportForm)
 			return nil
+		case tcell.KeyCtrlT: // Insert Selection into Pattern
+			a.insertSelection()
+			return nil
+		case tcell.KeyCtrlR: // Generalise Inserted Selection
+			a.generaliseSelection()
+			return nil
+		case tcell.KeyCtrlO: // Capture Sub-selection
+			a.groupSelection()
+			return nil
+		case tcell.KeyCtrlN: // Add Pattern to the Highlighted Patterns
+			a.pinPattern()
+			return nil
+		case tcell.KeyCtrlP: // Show Highlighted Patterns
+			a.showPatternPage()
+			return nil
+		case tcell.KeyCtrlS: // Show Text Processing Options
+			a.modalPages.AddPage(OptionsPage, a.optionsPage, true, true)
+			a.app.SetFocus(a.optionsForm)
+			return nil
+		case tcell.KeyCtrlG: // Show Code Generation
+			a.showCodePage()
+			return nil
 		case tcell.KeyTab:
+			if a.regexCompleter.isOpen() {
+				return event // Navigates the completions
+			}
 			a.cycleFocus(false)
 			return nil
 		case tcell.KeyBacktab:
+			if a.regexCompleter.isOpen() {
+				return event
+			}
 			a.cycleFocus(true)
 			return nil
 		}
@@ -105,6 +216,19 @@ func (a *App) setupEventHandlers() {
 	})
 }
 
+// modalPageNames lists the pages closed by Esc, in the order they are checked.
+var modalPageNames = []string{ResultPage, ExportPage, HistoryPage, RegexHelpPage, KeybindingsHelpPage, RefactorPage, PrettyPage, ImportPage, CodePage, SynthPage, ReferencePage, TutorialPage, OptionsPage, PatternsPage, PipelinePage, TableFilterPage, TimelineLayoutPage}
+
+// topModalPage returns the name of the first open modal page, or "" if none is open.
+func (a *App) topModalPage() string {
+	for _, name := range modalPageNames {
+		if a.modalPages.HasPage(name) {
+			return name
+		}
+	}
+	return ""
+}
+
 // handleViewNavigation provides advanced navigation for TextViews.
 func (a *App) handleViewNavigation(event *tcell.EventKey) *tcell.EventKey {
 	var view *tview.TextView
@@ -175,6 +299,51 @@ func (a *App) handleViewNavigation(event *tcell.EventKey) *tcell.EventKey {
 		return nil
 	}
 
+	if view == a.matchView {
+		switch event.Rune() {
+		case 't':
+			a.toggleTableMode()
+			return nil
+		case 'c':
+			a.toggleFreqView()
+			return nil
+		case 'm':
+			a.toggleStatsView()
+			return nil
+		case 'w':
+			a.toggleTimelineView()
+			return nil
+		}
+	}
+
+	// Line view of the Highlighted pane
+	if view == a.highlightedView {
+		opts := a.grepOpts
+		switch event.Rune() {
+		case 'v':
+			a.toggleLineView()
+			return nil
+		case 'i':
+			opts.Invert = !opts.Invert
+		case '+':
+			opts.Before, opts.After = opts.Before+1, opts.After+1
+		case '-':
+			opts.Before, opts.After = opts.Before-1, opts.After-1
+		case 'b':
+			opts.Before++
+		case 'B':
+			opts.Before--
+		case 'a':
+			opts.After++
+		case 'A':
+			opts.After--
+		default:
+			return event
+		}
+		a.setGrepOptions(opts)
+		return nil
+	}
+
 	return event
 }
 
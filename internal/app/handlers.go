@@ -3,7 +3,13 @@ package app
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"golang.design/x/clipboard"
 )
@@ -13,6 +19,9 @@ func (a *App) updateHighlight() {
 	regexStr := a.regexInput.GetText()
 	text := a.textArea.GetText()
 
+	a.updateSuiteView(regexStr)
+	a.updateTutorialView(regexStr)
+
 	// Reset match data
 	a.matches = nil
 	a.matchIndices = nil
@@ -20,7 +29,46 @@ func (a *App) updateHighlight() {
 	a.matchViewLines = nil
 	a.currentMatchIndex = -1
 
-	_, indices, matches, err := Search(regexStr, text)
+	a.matchRecords = nil
+	a.records = nil
+	a.searchUnits = nil
+	a.filterLines = nil
+	var indices [][]int
+	var matches [][]string
+	var err error
+	if a.recordOpts.Mode == RecordsNone && a.filter == nil {
+		_, indices, matches, err = Search(regexStr, text)
+	} else {
+		// The pattern is applied to each record, or each line, that passes the filter.
+		units, splitErr := FilterUnits(text, a.recordOpts)
+		if splitErr != nil {
+			a.highlightedView.SetText(fmt.Sprintf("[red]%s[-]\n%s", tview.Escape(splitErr.Error()), tview.Escape(text)))
+			a.matchView.SetText("")
+			return
+		}
+		a.searchUnits = units
+		var selected []int
+		if a.filter != nil {
+			selected = ApplyFilter(text, units, a.filter)
+			a.searchUnits = make([]Span, len(selected))
+			for i, unit := range selected {
+				a.searchUnits[i] = units[unit]
+			}
+			a.filterLines = UnitLines(text, a.searchUnits)
+		}
+
+		var recordOf []int
+		indices, matches, recordOf, err = SearchRecords(regexStr, text, a.searchUnits)
+		if a.recordOpts.Mode != RecordsNone {
+			if selected != nil {
+				for i, r := range recordOf {
+					recordOf[i] = selected[r]
+				}
+			}
+			a.records = units
+			a.matchRecords = recordOf
+		}
+	}
 
 	if err != nil {
 		a.highlightedView.SetText(fmt.Sprintf("[red]Invalid Regular Expression[-]\n%s", tview.Escape(text)))
@@ -28,18 +76,188 @@ func (a *App) updateHighlight() {
 		return
 	}
 
-	// If no regex, just show plain text and clear matches
-	if regexStr == "" {
-		a.highlightedView.SetText(tview.Escape(text))
+	a.matchIndices = indices
+	a.matches = matches
+	a.timeline, a.timelineErr = Timeline{}, nil
+	if a.timelineVisible || !a.timelineBucket.IsZero() {
+		a.groupLabel(&a.timelineOpts.Group) // Resets a group the pattern lost.
+		a.timeline, a.timelineErr = BuildTimeline(matches, a.timelineOpts)
+		if !a.timelineBucket.IsZero() {
+			a.keepBucketMatches()
+			indices = a.matchIndices
+		}
+	}
+	a.patternResults = a.searchPatterns(text)
+	a.pipeline, a.pipelineErr = nil, nil
+	if len(a.stages) > 0 && regexStr != "" {
+		a.pipeline, a.pipelineErr = RunPipeline(regexStr, a.matches, a.stages)
+	}
+
+	a.fields, a.fieldUnits = nil, nil
+	if a.splitOpts.Enabled && regexStr != "" {
+		a.fieldUnits = a.searchUnits
+		if a.fieldUnits == nil {
+			a.fieldUnits = []Span{{0, len(text)}}
+		}
+		a.fields, _ = SplitFields(regexStr, text, a.fieldUnits, a.splitOpts) // The pattern compiled above.
+	}
+
+	spans := alternatingSpans(indices)
+	lineMatches := indices
+	if len(a.patternResults) > 0 {
+		matchSets := [][][]int{indices}
+		colors := []string{patternColors[0]}
+		lineMatches = slices.Clone(indices)
+		for _, r := range a.patternResults {
+			matchSets = append(matchSets, r.indices)
+			colors = append(colors, patternColor(r.entry))
+			lineMatches = append(lineMatches, r.indices...)
+		}
+		spans = segmentSpans(OverlapSegments(matchSets), colors)
+	}
+
+	switch {
+	case a.lineView:
+		a.updateLineView(text, lineMatches, spans)
+	case !a.timelineBucket.IsZero():
+		a.updateBucketView(text, spans)
+	case a.filter != nil:
+		a.updateFilterView(text, spans)
+	default:
+		a.updateHighlightedView(text, spans)
+	}
+
+	if a.tableMode {
+		a.updateMatchTable()
+	}
+	if a.freqVisible {
+		a.updateFreqView()
+	}
+	if a.statsVisible {
+		a.updateStatsView()
+	}
+	if a.timelineVisible {
+		a.updateTimelineView()
+	}
+
+	// If no regex, just show the highlighted text
+	if regexStr == "" && len(a.patternResults) == 0 {
 		a.matchView.SetText("")
 		return
 	}
+	if a.fields != nil {
+		a.updateFieldsView(text)
+		return
+	}
+	a.updateMatchView(a.matches)
+}
 
-	a.matchIndices = indices
-	a.matches = matches
+// toggleTableMode switches the Matches pane between the list and the table.
+func (a *App) toggleTableMode() {
+	a.tableMode = !a.tableMode
+	var from, to tview.Primitive = a.matchView, a.matchTable
+	page := MatchTablePage
+	if !a.tableMode {
+		from, to = to, from
+		page = MatchListPage
+	}
+	a.matchPages.SwitchToPage(page)
+	if i := slices.Index(a.focusables, from); i >= 0 {
+		a.focusables[i] = to
+	}
+	a.updateHighlight()
+	a.app.SetFocus(to)
+}
 
-	a.updateHighlightedView(text, a.matchIndices)
-	a.updateMatchView(a.matches)
+// handleTableKey sorts and filters the column of the selected cell.
+func (a *App) handleTableKey(event *tcell.EventKey) *tcell.EventKey {
+	_, column := a.matchTable.GetSelection()
+	switch event.Rune() {
+	case 't':
+		a.toggleTableMode()
+	case 's':
+		switch {
+		case a.tableOpts.SortColumn != column:
+			a.tableOpts.SortColumn, a.tableOpts.Descending = column, false
+		case !a.tableOpts.Descending:
+			a.tableOpts.Descending = true
+		default:
+			a.tableOpts.SortColumn = -1
+		}
+		a.updateMatchTable()
+	case 'f':
+		if column < len(a.table.Headers) {
+			a.showTableFilter(column)
+		}
+	case 'F':
+		a.tableOpts.Filters = nil
+		a.updateMatchTable()
+	case 'c':
+		a.toggleFreqView()
+	case 'm':
+		a.toggleStatsView()
+	case 'w':
+		a.toggleTimelineView()
+	default:
+		return event
+	}
+	return nil
+}
+
+// showTableFilter asks for the pattern that the cells of the column must match.
+func (a *App) showTableFilter(column int) {
+	input := tview.NewInputField().SetLabel(LabelRegex)
+	if re := a.tableOpts.Filters[column]; re != nil {
+		input.SetText(re.String())
+	}
+	input.SetBorder(true).SetTitle(fmt.Sprintf(TitleTableFilterFormat, a.table.Headers[column]))
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter {
+			return
+		}
+		filter := input.GetText()
+		if filter == "" {
+			delete(a.tableOpts.Filters, column)
+		} else {
+			re, err := regexp.Compile(filter)
+			if err != nil {
+				a.showResultModal(fmt.Sprintf("Error: %v", err), true)
+				return
+			}
+			if a.tableOpts.Filters == nil {
+				a.tableOpts.Filters = make(map[int]*regexp.Regexp)
+			}
+			a.tableOpts.Filters[column] = re
+		}
+		a.modalPages.RemovePage(TableFilterPage)
+		a.app.SetFocus(a.matchTable)
+		a.updateMatchTable()
+	})
+	a.modalPages.AddPage(TableFilterPage, centered(input, 60, 3), true, true)
+	a.app.SetFocus(input)
+}
+
+// searchPatterns searches the text with the enabled additional patterns, like the main one.
+// Invalid patterns are skipped; the pattern list shows them.
+func (a *App) searchPatterns(text string) []patternResult {
+	var results []patternResult
+	for i, entry := range a.patterns {
+		if !entry.enabled {
+			continue
+		}
+		var indices [][]int
+		var matches [][]string
+		var err error
+		if a.searchUnits != nil {
+			indices, matches, _, err = SearchRecords(entry.pattern, text, a.searchUnits)
+		} else {
+			_, indices, matches, err = Search(entry.pattern, text)
+		}
+		if err == nil {
+			results = append(results, patternResult{entry: i, indices: indices, matches: matches})
+		}
+	}
+	return results
 }
 
 func (a *App) handleExport() {
@@ -50,18 +268,82 @@ func (a *App) handleExport() {
 	destIndex, _ := a.exportForm.GetFormItemByLabel(LabelOutputTarget).(*tview.DropDown).GetCurrentOption()
 	groupInput := a.exportForm.GetFormItemByLabel(LabelGroupNumbers).(*tview.InputField).GetText()
 	customFormatInput := a.exportForm.GetFormItemByLabel(LabelCustomFormat).(*tview.InputField).GetText()
+	templateInput := a.exportForm.GetFormItemByLabel(LabelTemplate).(*tview.InputField).GetText()
 	filePathInput := a.exportForm.GetFormItemByLabel(LabelFilePath).(*tview.InputField).GetText()
+	headerIndex, _ := a.exportForm.GetFormItemByLabel(LabelHeaderRow).(*tview.DropDown).GetCurrentOption()
 
 	var outputData []byte
 	var err error
 
 	switch formatIndex {
 	case 0: // JSON (all content)
-		outputData, err = GenerateExportJSONAll(a.GetRegexInput(), a.matches)
+		if a.fields != nil {
+			outputData, err = GenerateFieldsExportJSON(a.GetRegexInput(), a.fields, "")
+		} else if a.pipeline != nil {
+			outputData, err = GeneratePipelineExportJSON(a.GetRegexInput(), a.stages, a.pipeline, a.matchRecords, "")
+		} else if a.records != nil {
+			outputData, err = GenerateRecordExportJSONAll(a.GetRegexInput(), GroupByRecord(a.matches, a.matchRecords))
+		} else {
+			outputData, err = GenerateExportJSONAll(a.GetRegexInput(), a.matches)
+		}
 	case 1: // JSON (specific groups)
-		outputData, err = GenerateExportJSONGroups(a.GetRegexInput(), a.matches, groupInput)
+		if a.fields != nil {
+			outputData, err = GenerateFieldsExportJSON(a.GetRegexInput(), a.fields, groupInput)
+		} else if a.pipeline != nil {
+			outputData, err = GeneratePipelineExportJSON(a.GetRegexInput(), a.stages, a.pipeline, a.matchRecords, groupInput)
+		} else if a.records != nil {
+			outputData, err = GenerateRecordExportJSONGroups(a.GetRegexInput(), GroupByRecord(a.matches, a.matchRecords), groupInput)
+		} else {
+			outputData, err = GenerateExportJSONGroups(a.GetRegexInput(), a.matches, groupInput)
+		}
 	case 2: // Custom format
-		outputData, err = GenerateExportCustom(a.matches, customFormatInput)
+		if a.fields != nil {
+			outputData, err = GenerateExportCustom(FieldMatches(a.textArea.GetText(), a.fieldUnits, a.fields), customFormatInput)
+		} else if a.records != nil {
+			outputData, err = GenerateRecordExportCustom(GroupByRecord(a.matches, a.matchRecords), customFormatInput)
+		} else {
+			outputData, err = GenerateExportCustom(a.matches, customFormatInput)
+		}
+	case 3, 4: // CSV or TSV of the groups
+		comma := ','
+		if formatIndex == 4 {
+			comma = '\t'
+		}
+		outputData, err = GenerateExportCSV(a.GetRegexInput(), a.matches, groupInput, HeaderMode(headerIndex), comma)
+	case 5: // Lines of the line view
+		if !a.lineView && a.filter == nil {
+			err = fmt.Errorf("no lines are filtered: press v in the Highlighted pane or set a filter with Ctrl+S")
+			break
+		}
+		outputData = []byte(RenderGrepLines(a.textArea.GetText(), a.grepLines))
+	case 6: // CSV of the split fields
+		if a.fields == nil {
+			err = fmt.Errorf("no fields to export: choose a split mode with Ctrl+S")
+			break
+		}
+		outputData, err = GenerateFieldsExportCSV(a.fields)
+	case 7: // CSV of the visible rows of the table
+		if !a.tableMode {
+			err = fmt.Errorf("the table is not shown: press t in the Matches pane")
+			break
+		}
+		outputData, err = GenerateTableExportCSV(a.table.Headers, a.tableRows)
+	case 8: // Counts of the values of the group of the frequency panel
+		outputData = []byte(RenderFrequencies(Frequencies(a.matches, a.freqGroup, a.freqByValue)))
+	case 9: // Statistics of the group of the statistics panel
+		outputData = []byte(RenderStats(NumericStats(a.matches, a.statsGroup)))
+	case 10: // Buckets of the timeline panel
+		timeline, timelineErr := a.timeline, a.timelineErr
+		if !a.timelineVisible && a.timelineBucket.IsZero() {
+			timeline, timelineErr = BuildTimeline(a.matches, a.timelineOpts)
+		}
+		if err = timelineErr; err == nil {
+			outputData, err = GenerateTimelineExportCSV(timeline.Buckets)
+		}
+	case 11: // NDJSON with the offsets of each match
+		outputData, err = GenerateExportNDJSON(a.GetRegexInput(), a.textArea.GetText(), a.matchIndices, a.matches)
+	case 12: // Go template
+		outputData, err = GenerateExportTemplate(a.GetRegexInput(), a.textArea.GetText(), a.matchIndices, a.matches, templateInput)
 	}
 
 	if err != nil {
@@ -84,6 +366,460 @@ func (a *App) handleExport() {
 	a.updateHistory()
 }
 
+// insertLibraryPattern puts a pattern chosen in the library into the regex input, either
+// at the cursor or in place of the whole pattern.
+func (a *App) insertLibraryPattern(pattern string, replace bool) {
+	a.modalPages.RemovePage(RegexHelpPage)
+	a.app.SetFocus(a.regexInput)
+	if replace {
+		a.SetRegexInput(pattern)
+		return
+	}
+	// Pasting is the only way to insert at the cursor of an input field.
+	a.regexInput.PasteHandler()(pattern, func(p tview.Primitive) {})
+	a.updateHighlight()
+}
+
+// handleRefactor applies a refactor action to the current pattern and keeps the export
+// settings pointing at the same groups.
+func (a *App) handleRefactor(action string) {
+	a.modalPages.RemovePage(RefactorPage)
+	a.app.SetFocus(a.regexInput)
+	pattern := a.GetRegexInput()
+
+	if action == ActPretty {
+		pretty, err := PrettyPattern(pattern)
+		if err != nil {
+			a.showResultModal(fmt.Sprintf("Error refactoring pattern: %v", err), true)
+			return
+		}
+		a.prettyView.SetText(pretty)
+		a.modalPages.AddPage(PrettyPage, a.prettyView, true, true)
+		a.app.SetFocus(a.prettyView)
+		return
+	}
+
+	var refactor func(string) (RefactorResult, error)
+	switch action {
+	case ActNonCapturing:
+		refactor = RefactorToNonCapturing
+	case ActCapturing:
+		refactor = RefactorToCapturing
+	case ActNameGroups:
+		refactor = RefactorNameGroups
+	case ActAlternations:
+		refactor = RefactorFactorAlternations
+	case ActMinify:
+		refactor = RefactorMinify
+	default:
+		return
+	}
+
+	result, err := refactor(pattern)
+	if err != nil {
+		a.showResultModal(fmt.Sprintf("Error refactoring pattern: %v", err), true)
+		return
+	}
+
+	customFormat := a.exportForm.GetFormItemByLabel(LabelCustomFormat).(*tview.InputField)
+	format, formatErr := RemapCustomFormat(customFormat.GetText(), result.GroupMap)
+	customFormat.SetText(format)
+	groupNumbers := a.exportForm.GetFormItemByLabel(LabelGroupNumbers).(*tview.InputField)
+	groupNumbers.SetText(RemapGroupNumbers(groupNumbers.GetText(), result.GroupMap))
+
+	a.regexInput.SetText(result.Pattern)
+	a.updateHighlight()
+	if formatErr != nil {
+		a.showResultModal(fmt.Sprintf("Pattern refactored, but %v", formatErr), false)
+	}
+}
+
+// handleImport translates the pattern from the import form and loads it if RE2 accepts the result.
+func (a *App) handleImport() {
+	_, dialect := a.importForm.GetFormItemByLabel(LabelDialect).(*tview.DropDown).GetCurrentOption()
+	input := a.importForm.GetFormItemByLabel(LabelForeignRegex).(*tview.InputField).GetText()
+
+	result, err := TranslateToRE2(dialect, input)
+	if err != nil {
+		a.showResultModal(fmt.Sprintf("Error translating pattern: %v", err), true)
+		return
+	}
+	if _, err := regexp.Compile(result.Pattern); err != nil {
+		a.showResultModal(fmt.Sprintf("Translated pattern is not valid RE2: %v\n\n%s\n\n%s", err, result.Pattern, result.Report()), true)
+		return
+	}
+
+	a.modalPages.RemovePage(ImportPage)
+	a.app.SetFocus(a.regexInput)
+	a.regexInput.SetText(result.Pattern)
+	a.updateHighlight()
+	if report := result.Report(); report != "" {
+		a.showResultModal(report, false)
+	}
+}
+
+// showCodePage opens the code generation page for the current pattern.
+func (a *App) showCodePage() {
+	_, lang := a.codeForm.GetFormItemByLabel(LabelLanguage).(*tview.DropDown).GetCurrentOption()
+	a.updateCodeView(lang)
+	a.modalPages.AddPage(CodePage, a.codePage, true, true)
+	a.app.SetFocus(a.codeForm)
+}
+
+// updateCodeView regenerates the snippet for the given language.
+func (a *App) updateCodeView(lang string) {
+	snippet, err := GenerateCode(lang, a.GetRegexInput())
+	if err != nil {
+		a.codeView.SetText("")
+		a.codeWarningsView.SetText(fmt.Sprintf("Cannot generate code: %v", err))
+		return
+	}
+	a.codeView.SetText(snippet.Code)
+	a.codeWarningsView.SetText("- " + strings.Join(snippet.Warnings, "\n- "))
+	if len(snippet.Warnings) == 0 {
+		a.codeWarningsView.SetText("(none)")
+	}
+}
+
+func (a *App) handleCopyCode() {
+	code := a.codeView.GetText(false)
+	if code == "" {
+		return
+	}
+	if err := a.saveToClipboard([]byte(code)); err != nil {
+		a.showResultModal(fmt.Sprintf("Error saving data: %v", err), true)
+		return
+	}
+	a.modalPages.RemovePage(CodePage)
+	a.app.SetFocus(a.regexInput)
+}
+
+// toggleSuiteView shows or hides the test suite panel next to the match view.
+func (a *App) toggleSuiteView() {
+	if a.suite == nil {
+		a.showResultModal("No test suite loaded. Start with --suite FILE.", true)
+		return
+	}
+	if a.suiteVisible {
+		a.bottomPane.RemoveItem(a.suiteView)
+		a.focusables = slices.DeleteFunc(a.focusables, func(p tview.Primitive) bool { return p == a.suiteView })
+		if a.suiteView.HasFocus() {
+			a.app.SetFocus(a.regexInput)
+		}
+	} else {
+		a.bottomPane.AddItem(a.suiteView, 0, 1, false)
+		a.focusables = append(a.focusables, a.suiteView)
+	}
+	a.suiteVisible = !a.suiteVisible
+}
+
+// toggleSidePanel shows or hides a panel next to the match view, such as the frequency panel.
+func (a *App) toggleSidePanel(view tview.Primitive, visible *bool, update func()) {
+	*visible = !*visible
+	if !*visible {
+		a.bottomPane.RemoveItem(view)
+		a.focusables = slices.DeleteFunc(a.focusables, func(p tview.Primitive) bool { return p == view })
+		if view.HasFocus() {
+			a.app.SetFocus(a.matchPages)
+		}
+	} else {
+		a.bottomPane.AddItem(view, 0, 1, false)
+		a.focusables = append(a.focusables, view)
+		update()
+		a.app.SetFocus(view)
+	}
+}
+
+func (a *App) toggleFreqView() {
+	a.toggleSidePanel(a.freqView, &a.freqVisible, a.updateFreqView)
+}
+
+func (a *App) toggleStatsView() {
+	a.toggleSidePanel(a.statsView, &a.statsVisible, a.updateStatsView)
+}
+
+// toggleTimelineView shows or hides the timeline panel. Hiding it shows all the matches again.
+func (a *App) toggleTimelineView() {
+	a.timelineBucket = time.Time{}
+	a.toggleSidePanel(a.timelineList, &a.timelineVisible, a.updateHighlight)
+	if !a.timelineVisible {
+		a.updateHighlight()
+	}
+}
+
+// keepBucketMatches keeps the matches whose timestamp is in the selected bucket of the timeline.
+func (a *App) keepBucketMatches() {
+	var indices [][]int
+	var matches [][]string
+	var records []int
+	for i := range a.matches {
+		if a.timeline.InBucket(i, a.timelineBucket, a.timelineOpts.Bucket) {
+			indices = append(indices, a.matchIndices[i])
+			matches = append(matches, a.matches[i])
+			if a.matchRecords != nil {
+				records = append(records, a.matchRecords[i])
+			}
+		}
+	}
+	a.matchIndices, a.matches = indices, matches
+	if a.matchRecords != nil {
+		a.matchRecords = records
+	}
+}
+
+// selectBucket restricts the matches to a bucket of the timeline, or shows them all again
+// if the bucket is already selected.
+func (a *App) selectBucket(index int) {
+	if index >= len(a.timeline.Buckets) {
+		return
+	}
+	start := a.timeline.Buckets[index].Start
+	if start.Equal(a.timelineBucket) {
+		start = time.Time{}
+	}
+	a.timelineBucket = start
+	a.updateHighlight()
+}
+
+// handleTimelineKey changes the timestamp group, the layout and the bucket size of the timeline.
+func (a *App) handleTimelineKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Rune() {
+	case 'g':
+		a.timelineOpts.Group = a.nextGroup(a.timelineOpts.Group)
+	case 'b':
+		i := slices.Index(BucketSizes, a.timelineOpts.Bucket)
+		a.timelineOpts.Bucket = BucketSizes[(i+1)%len(BucketSizes)]
+	case 'y':
+		a.showTimelineLayout()
+		return nil
+	case 'x':
+	case 'w':
+		a.toggleTimelineView()
+		return nil
+	default:
+		return event
+	}
+	a.timelineBucket = time.Time{}
+	a.updateHighlight()
+	return nil
+}
+
+// showTimelineLayout asks for the layout of the timestamps.
+func (a *App) showTimelineLayout() {
+	input := tview.NewInputField().SetLabel(LabelLayout).SetText(a.timelineOpts.Layout)
+	input.SetBorder(true).SetTitle(TitleTimelineLayout)
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter {
+			return
+		}
+		a.timelineOpts.Layout = strings.TrimSpace(input.GetText())
+		a.timelineBucket = time.Time{}
+		a.modalPages.RemovePage(TimelineLayoutPage)
+		a.app.SetFocus(a.timelineList)
+		a.updateHighlight()
+	})
+	a.modalPages.AddPage(TimelineLayoutPage, centered(input, 80, 3), true, true)
+	a.app.SetFocus(input)
+}
+
+// nextGroup returns the group after the given one in the pattern, back to the whole match after the last.
+func (a *App) nextGroup(group int) int {
+	groups := 1
+	if re, err := regexp.Compile(a.GetRegexInput()); err == nil {
+		groups = re.NumSubexp() + 1
+	}
+	return (group + 1) % groups
+}
+
+// handleFreqKey changes the counted group and the order of the frequency panel.
+func (a *App) handleFreqKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Rune() {
+	case 'g':
+		a.freqGroup = a.nextGroup(a.freqGroup)
+	case 'o':
+		a.freqByValue = !a.freqByValue
+	case 'c':
+		a.toggleFreqView()
+		return nil
+	default:
+		return event
+	}
+	a.updateFreqView()
+	return nil
+}
+
+// handleStatsKey changes the summarised group of the statistics panel.
+func (a *App) handleStatsKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Rune() {
+	case 'g':
+		a.statsGroup = a.nextGroup(a.statsGroup)
+	case 'm':
+		a.toggleStatsView()
+		return nil
+	default:
+		return event
+	}
+	a.updateStatsView()
+	return nil
+}
+
+// markExample records the text selected in the text area as an example for pattern synthesis.
+// Without a selection, all examples are cleared.
+func (a *App) markExample(positive bool) {
+	_, start, end := a.textArea.GetSelection()
+	if start == end {
+		a.clearExamples()
+		return
+	}
+	span := Span{Start: start, End: end}
+	if positive {
+		a.positiveSpans = append(a.positiveSpans, span)
+	} else {
+		a.negativeSpans = append(a.negativeSpans, span)
+	}
+	a.textArea.SetTitle(fmt.Sprintf(TitleTextExamples, len(a.positiveSpans), len(a.negativeSpans)))
+}
+
+// clearExamples forgets the marked examples, whose offsets are no longer valid once the text is edited.
+func (a *App) clearExamples() {
+	if len(a.positiveSpans) == 0 && len(a.negativeSpans) == 0 {
+		return
+	}
+	a.positiveSpans = nil
+	a.negativeSpans = nil
+	a.textArea.SetTitle(TitleText)
+}
+
+// showSynthPage proposes patterns for the marked examples. Moving through the list
+// previews each candidate in the Highlighted view.
+func (a *App) showSynthPage() {
+	candidates, err := Synthesize(a.textArea.GetText(), a.positiveSpans, a.negativeSpans)
+	if err != nil {
+		a.showResultModal(fmt.Sprintf("Cannot synthesize a pattern: %v\n\nSelect text and press F8 (positive) or F9 (negative).", err), true)
+		return
+	}
+	if len(candidates) == 0 {
+		a.showResultModal("No candidate matches all positive examples without matching a negative one.", true)
+		return
+	}
+
+	a.synthPattern = a.GetRegexInput()
+	// Adding the first item selects it, which previews it.
+	a.synthList.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		a.SetRegexInput(candidates[index].Pattern)
+	})
+	a.synthList.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		a.closeSynthPage(true)
+	})
+	a.synthList.Clear()
+	for _, c := range candidates {
+		a.synthList.AddItem(fmt.Sprintf("%s  (%d matches)", tview.Escape(c.Pattern), c.Matches), "", 0, nil)
+	}
+	a.modalPages.AddPage(SynthPage, a.synthPage, true, true)
+	a.app.SetFocus(a.synthList)
+}
+
+// closeSynthPage closes the synthesis page, keeping the previewed candidate or restoring the previous pattern.
+func (a *App) closeSynthPage(keep bool) {
+	a.modalPages.RemovePage(SynthPage)
+	a.app.SetFocus(a.regexInput)
+	if !keep {
+		a.SetRegexInput(a.synthPattern)
+	}
+}
+
+// insertSelection appends the text selected in the text area to the pattern, escaped,
+// and starts building on it.
+func (a *App) insertSelection() {
+	_, start, end := a.textArea.GetSelection()
+	if start == end {
+		a.showResultModal("Select text in the text area first.", true)
+		return
+	}
+	a.build = &patternBuild{prefix: a.GetRegexInput(), span: Span{Start: start, End: end}}
+	a.applyBuild()
+}
+
+// generaliseSelection steps the inserted selection to the next looser pattern, wrapping
+// around to the literal.
+func (a *App) generaliseSelection() {
+	b := a.currentBuild()
+	if b == nil {
+		return
+	}
+	b.level = (b.level + 1) % GeneraliseLevels
+	a.applyBuild()
+}
+
+// groupSelection wraps the part of the inserted selection that is now selected in a capture group.
+func (a *App) groupSelection() {
+	b := a.currentBuild()
+	if b == nil {
+		return
+	}
+	_, start, end := a.textArea.GetSelection()
+	if start == end || start < b.span.Start || end > b.span.End {
+		a.showResultModal("Select a part of the inserted text to capture.", true)
+		return
+	}
+	group := Span{Start: start - b.span.Start, End: end - b.span.Start}
+	for _, g := range b.groups {
+		if group.Start < g.End && group.End > g.Start {
+			a.showResultModal("Capture groups cannot overlap.", true)
+			return
+		}
+	}
+	b.groups = append(b.groups, group)
+	a.applyBuild()
+}
+
+// currentBuild returns the selection being built on, or nil with a message if the
+// pattern was edited since it was inserted.
+func (a *App) currentBuild() *patternBuild {
+	if a.build == nil || a.build.pattern != a.GetRegexInput() {
+		a.build = nil
+		a.showResultModal("Insert a selection with Ctrl+T first.", true)
+		return nil
+	}
+	return a.build
+}
+
+func (a *App) applyBuild() {
+	b := a.build
+	text := a.textArea.GetText()[b.span.Start:b.span.End]
+	b.pattern = b.prefix + BuildPattern(text, b.groups, b.level)
+	a.SetRegexInput(b.pattern)
+}
+
+// trySyntaxExample loads the example of a syntax reference entry into the main window,
+// keeping the current pattern and text to restore them afterwards.
+func (a *App) trySyntaxExample(entry SyntaxEntry) {
+	a.trial = &syntaxTrial{pattern: a.GetRegexInput(), text: a.textArea.GetText()}
+	a.modalPages.RemovePage(ReferencePage)
+	a.textArea.SetText(entry.Text, false)
+	a.SetRegexInput(entry.Example)
+	a.helpHintView.SetText(HintTrying)
+	a.app.SetFocus(a.regexInput)
+}
+
+// endSyntaxTrial restores the pattern and text replaced by a syntax example, and
+// optionally returns to the reference.
+func (a *App) endSyntaxTrial(reopen bool) {
+	trial := a.trial
+	a.trial = nil
+	a.textArea.SetText(trial.text, false)
+	a.SetRegexInput(trial.pattern)
+	if a.tutorial != nil {
+		a.helpHintView.SetText(HintTutorial)
+	} else {
+		a.helpHintView.SetText(HintHelp)
+	}
+	if reopen {
+		a.modalPages.AddPage(ReferencePage, a.referenceView, true, true)
+		a.app.SetFocus(a.referenceView.table)
+	}
+}
+
 func (a *App) saveToClipboard(data []byte) error {
 	if err := clipboard.Init(); err != nil {
 		return fmt.Errorf("failed to initialize clipboard: %v", err)
@@ -106,3 +842,272 @@ func (a *App) lastMatch() string {
 		return ""
 	}
 }
+
+// pinPattern adds the main pattern to the additional patterns and clears it for the next one.
+func (a *App) pinPattern() {
+	pattern := a.GetRegexInput()
+	if pattern == "" {
+		return
+	}
+	a.patterns = append(a.patterns, patternEntry{pattern: pattern, enabled: true})
+	a.SetRegexInput("")
+}
+
+// showPatternPage lists the additional patterns with their colors.
+func (a *App) showPatternPage() {
+	a.refreshPatternList()
+	a.modalPages.AddPage(PatternsPage, a.patternPage, true, true)
+	a.app.SetFocus(a.patternList)
+}
+
+// refreshPatternList rebuilds the pattern list, keeping the selection.
+func (a *App) refreshPatternList() {
+	current := a.patternList.GetCurrentItem()
+	counts := make(map[int]int)
+	for _, r := range a.patternResults {
+		counts[r.entry] = len(r.matches)
+	}
+
+	a.patternList.Clear()
+	for i, entry := range a.patterns {
+		status := "[gray]disabled[-]"
+		if _, err := regexp.Compile(entry.pattern); err != nil {
+			status = "[red]invalid[-]"
+		} else if entry.enabled {
+			status = fmt.Sprintf("%d matches", counts[i])
+		}
+		a.patternList.AddItem(fmt.Sprintf("%s  [-:-] %d. %s  (%s)", patternColor(i), i+1, tview.Escape(entry.pattern), status), "", 0, nil)
+	}
+	if len(a.patterns) == 0 {
+		a.patternList.AddItem("No patterns yet: Ctrl+N adds the main pattern.", "", 0, nil)
+	}
+	a.patternList.SetCurrentItem(min(current, a.patternList.GetItemCount()-1))
+}
+
+// handlePatternListKey toggles, deletes and swaps the selected pattern.
+func (a *App) handlePatternListKey(event *tcell.EventKey) *tcell.EventKey {
+	index := a.patternList.GetCurrentItem()
+	if index >= len(a.patterns) {
+		return event
+	}
+	switch {
+	case event.Key() == tcell.KeyRune && event.Rune() == ' ':
+		a.patterns[index].enabled = !a.patterns[index].enabled
+	case event.Key() == tcell.KeyRune && event.Rune() == 'd':
+		a.patterns = slices.Delete(a.patterns, index, index+1)
+	case event.Key() == tcell.KeyEnter:
+		// The main pattern takes the place of the selected one.
+		main := a.GetRegexInput()
+		a.regexInput.SetText(a.patterns[index].pattern)
+		if main == "" {
+			a.patterns = slices.Delete(a.patterns, index, index+1)
+		} else {
+			a.patterns[index] = patternEntry{pattern: main, enabled: true}
+		}
+	default:
+		return event
+	}
+	a.updateHighlight()
+	a.refreshPatternList()
+	return nil
+}
+
+// handleOptions applies the text processing options.
+func (a *App) handleOptions() {
+	mode, _ := a.optionsForm.GetFormItemByLabel(LabelRecords).(*tview.DropDown).GetCurrentOption()
+	separator := a.optionsForm.GetFormItemByLabel(LabelSeparator).(*tview.InputField).GetText()
+
+	filterExpr := a.optionsForm.GetFormItemByLabel(LabelFilter).(*tview.InputField).GetText()
+	patternUse, _ := a.optionsForm.GetFormItemByLabel(LabelPatternUse).(*tview.DropDown).GetCurrentOption()
+	limitInput := strings.TrimSpace(a.optionsForm.GetFormItemByLabel(LabelSplitLimit).(*tview.InputField).GetText())
+
+	opts := RecordOptions{Mode: RecordMode(mode), Separator: separator}
+	if _, err := SplitRecords("", opts); err != nil {
+		a.showResultModal(fmt.Sprintf("Error: %v", err), true)
+		return
+	}
+	filter, err := ParseFilter(filterExpr)
+	if err != nil {
+		a.showResultModal(fmt.Sprintf("Error: %v", err), true)
+		return
+	}
+	limit := 0
+	if limitInput != "" {
+		if limit, err = strconv.Atoi(limitInput); err != nil || limit < 0 {
+			a.showResultModal(fmt.Sprintf("Error: invalid split limit %q", limitInput), true)
+			return
+		}
+	}
+	a.recordOpts = opts
+	a.filter = filter
+	a.splitOpts = SplitOptions{Enabled: patternUse > 0, Limit: limit, KeepDelimiters: patternUse == 2}
+	a.modalPages.RemovePage(OptionsPage)
+	a.app.SetFocus(a.regexInput)
+	a.updateHighlight()
+}
+
+// showPipelinePage shows the stages of the pipeline for editing. The form is rebuilt
+// whenever a stage is added or removed.
+func (a *App) showPipelinePage(stages []Stage) {
+	form := tview.NewForm()
+	for i, stage := range stages {
+		form.AddInputField(fmt.Sprintf(LabelStageGroup, i+2), stage.Group, 12, nil, nil)
+		form.AddInputField(fmt.Sprintf(LabelStagePattern, i+2), stage.Pattern, 40, nil, nil)
+	}
+	formStages := func() []Stage {
+		stages := make([]Stage, form.GetFormItemCount()/2)
+		for i := range stages {
+			stages[i].Group = strings.TrimSpace(form.GetFormItem(2 * i).(*tview.InputField).GetText())
+			stages[i].Pattern = form.GetFormItem(2*i + 1).(*tview.InputField).GetText()
+		}
+		return stages
+	}
+	form.AddButton(ButtonAddStage, func() {
+		a.showPipelinePage(append(formStages(), Stage{}))
+	})
+	form.AddButton(ButtonRemoveStage, func() {
+		stages := formStages()
+		a.showPipelinePage(stages[:max(len(stages)-1, 0)])
+	})
+	form.AddButton(ButtonApply, func() { a.handlePipeline(formStages()) })
+	form.AddButton(ButtonCancel, func() {
+		a.modalPages.RemovePage(PipelinePage)
+		a.app.SetFocus(a.regexInput)
+	})
+	form.SetBorder(true).SetTitle(TitlePipeline).SetTitleAlign(tview.AlignLeft)
+
+	a.modalPages.AddPage(PipelinePage, centered(form, 80, 4*len(stages)+5), true, true)
+	a.app.SetFocus(form)
+}
+
+// handlePipeline applies the stages of the pipeline page. Stages without a pattern are
+// dropped; the groups are checked against the patterns when the pipeline runs.
+func (a *App) handlePipeline(stages []Stage) {
+	var kept []Stage
+	for i, stage := range stages {
+		if stage.Pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(stage.Pattern); err != nil {
+			a.showResultModal(fmt.Sprintf("Error: stage %d: %v", i+2, err), true)
+			return
+		}
+		kept = append(kept, stage)
+	}
+	a.stages = kept
+	a.modalPages.RemovePage(PipelinePage)
+	a.app.SetFocus(a.regexInput)
+	a.updateHighlight()
+}
+
+// toggleLineView switches the Highlighted pane between the whole text and the line view.
+func (a *App) toggleLineView() {
+	a.lineView = !a.lineView
+	a.grepLines = nil
+	a.updateHighlight()
+}
+
+// setGrepOptions changes the selected lines and context of the line view, turning it on.
+func (a *App) setGrepOptions(opts GrepOptions) {
+	a.grepOpts = GrepOptions{Before: max(opts.Before, 0), After: max(opts.After, 0), Invert: opts.Invert}
+	a.lineView = true
+	a.updateHighlight()
+}
+
+// loadTutorial returns the tutorial being followed, or a new one with the saved progress.
+func (a *App) loadTutorial() *tutorialState {
+	if a.tutorial != nil {
+		return a.tutorial
+	}
+	if a.trial != nil {
+		a.endSyntaxTrial(false)
+	}
+	path := TutorialProgressPath(a.historyFilePath)
+	progress, err := LoadTutorialProgress(path)
+	if err != nil {
+		a.showResultModal(fmt.Sprintf("Could not load the tutorial progress, starting afresh: %v", err), true)
+	}
+	return &tutorialState{progress: progress, progressPath: path, pattern: a.GetRegexInput(), text: a.textArea.GetText()}
+}
+
+// showExercises lists the exercises of the tutorial. The locked ones can't be started.
+func (a *App) showExercises() {
+	t := a.loadTutorial()
+	a.exerciseList.Clear()
+	current := -1
+	for i, exercise := range Exercises {
+		status := "  "
+		switch {
+		case t.progress.Solved[exercise.ID]:
+			status = "[green]✓[-] "
+		case !t.progress.Unlocked(i):
+			status = "[gray]🔒[-]"
+		case current < 0:
+			current = i
+		}
+		a.exerciseList.AddItem(fmt.Sprintf("%s %2d. %s", status, i+1, exercise.Title), tview.Escape(exercise.Goal), 0, nil)
+	}
+	if a.tutorial != nil {
+		current = a.tutorial.index
+		a.exerciseList.AddItem("Leave the tutorial", "Restore your pattern and text", 0, nil)
+	}
+	a.exerciseList.SetCurrentItem(max(current, 0))
+	a.exerciseList.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index == len(Exercises) {
+			a.modalPages.RemovePage(TutorialPage)
+			a.leaveTutorial()
+		} else if t.progress.Unlocked(index) {
+			a.startExercise(t, index)
+		}
+	})
+	a.modalPages.AddPage(TutorialPage, a.exercisePage, true, true)
+	a.app.SetFocus(a.exerciseList)
+}
+
+// startExercise shows an exercise: its text replaces the text input and the pattern is cleared.
+func (a *App) startExercise(t *tutorialState, index int) {
+	if a.tutorial == nil {
+		a.bottomPane.AddItem(a.tutorialView, 0, 1, false)
+		a.focusables = append(a.focusables, a.tutorialView)
+	}
+	a.tutorial = t
+	t.index, t.hints, t.solved = index, 0, false
+	a.modalPages.RemovePage(TutorialPage)
+
+	text, _, _ := ParseExercise(Exercises[index].Text)
+	a.textArea.SetText(text, false)
+	a.SetRegexInput("")
+	a.helpHintView.SetText(HintTutorial)
+	a.app.SetFocus(a.regexInput)
+}
+
+// nextExercise moves on to the next exercise once the current one is solved.
+func (a *App) nextExercise() bool {
+	t := a.tutorial
+	if t == nil || !t.solved || t.index+1 >= len(Exercises) {
+		return false
+	}
+	a.startExercise(t, t.index+1)
+	return true
+}
+
+// showHint reveals the next hint of the current exercise.
+func (a *App) showHint() {
+	if t := a.tutorial; t != nil && t.hints < len(Exercises[t.index].Hints) {
+		t.hints++
+		a.updateHighlight()
+	}
+}
+
+// leaveTutorial restores the pattern and text replaced by the tutorial. The progress is
+// saved as soon as an exercise is solved.
+func (a *App) leaveTutorial() {
+	t := a.tutorial
+	a.tutorial = nil
+	a.bottomPane.RemoveItem(a.tutorialView)
+	a.focusables = slices.DeleteFunc(a.focusables, func(p tview.Primitive) bool { return p == a.tutorialView })
+	a.textArea.SetText(t.text, false)
+	a.SetRegexInput(t.pattern)
+	a.helpHintView.SetText(HintHelp)
+	a.app.SetFocus(a.regexInput)
+}
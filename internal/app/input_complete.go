@@ -0,0 +1,90 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// completer adds context-aware completion to the end of an input field. tview doesn't
+// expose the cursor of an input field, so completions are only offered for the end of the
+// text, after it was extended there; accepting one leaves the cursor at the end. The
+// completer must be told about every change of the text through track.
+type completer struct {
+	field       *tview.InputField
+	complete    func(before string) (int, []Completion)
+	lastText    string
+	extended    bool         // Whether the last change only added text at the end.
+	start       int          // Where the partial text being completed starts.
+	completions []Completion // The open drop-down, nil if it is closed.
+}
+
+// newCompleter attaches completion to the field. Tab and Shift+Tab move through the
+// drop-down, Enter accepts the selected entry.
+func newCompleter(field *tview.InputField, complete func(before string) (int, []Completion)) *completer {
+	c := &completer{field: field, complete: complete, lastText: field.GetText()}
+	field.SetAutocompleteUseTags(false)
+	field.SetAutocompleteFunc(c.autocomplete)
+	field.SetAutocompletedFunc(c.autocompleted)
+	field.SetBlurFunc(func() { c.completions = nil })
+	return c
+}
+
+// track records whether the text was extended at its end, as when typing there.
+func (c *completer) track(text string) {
+	c.extended = len(text) > len(c.lastText) && strings.HasPrefix(text, c.lastText)
+	c.lastText = text
+}
+
+// isOpen reports whether the drop-down is shown.
+func (c *completer) isOpen() bool {
+	return len(c.completions) > 0
+}
+
+// handleKey turns Tab and Shift+Tab into drop-down navigation while it is open.
+// Escape closes the drop-down.
+func (c *completer) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if !c.isOpen() {
+		return event
+	}
+	switch event.Key() {
+	case tcell.KeyTab:
+		return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+	case tcell.KeyBacktab:
+		return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+	case tcell.KeyEsc:
+		c.completions = nil
+	}
+	return event
+}
+
+func (c *completer) autocomplete(text string) []string {
+	c.completions = nil
+	if !c.extended || text != c.lastText {
+		return nil
+	}
+	start, completions := c.complete(text)
+	c.start = start
+	c.completions = completions
+	entries := make([]string, len(completions))
+	for i, completion := range completions {
+		entries[i] = completion.Display
+	}
+	return entries
+}
+
+func (c *completer) autocompleted(text string, index int, source int) bool {
+	if source == tview.AutocompletedNavigate {
+		return false
+	}
+	if index < 0 || index >= len(c.completions) {
+		c.completions = nil
+		return true
+	}
+
+	// SetText leaves the cursor at the end, behind the completion.
+	c.field.SetText(c.field.GetText()[:c.start] + c.completions[index].Insert)
+	c.completions = nil
+	return true
+}
@@ -0,0 +1,235 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// LibraryView is the F2 pattern library: a filterable table of entries with a preview of
+// how the selected entry matches the current text.
+type LibraryView struct {
+	*tview.Box
+	table        *tview.Table
+	searchField  *tview.InputField
+	preview      *tview.TextView
+	flex         *tview.Flex
+	requestFocus func(p tview.Primitive) // Callback to request application focus
+	entries      []LibraryEntry          // All entries
+	filtered     []LibraryEntry          // Entries matching the filter
+	text         string                  // The text previewed against
+	onSelect     func(pattern string, replace bool)
+	onClose      func()
+}
+
+// NewLibraryView creates a new LibraryView.
+func NewLibraryView(requestFocus func(p tview.Primitive)) *LibraryView {
+	lv := &LibraryView{
+		Box:          tview.NewBox(),
+		table:        tview.NewTable().SetSelectable(true, false).SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkCyan)),
+		searchField:  tview.NewInputField().SetLabel("Filter: "),
+		preview:      tview.NewTextView(),
+		requestFocus: requestFocus,
+	}
+
+	lv.table.SetBorder(true).SetTitle(TitleLibrary)
+	lv.searchField.SetBorder(true)
+	lv.preview.SetBorder(true).SetTitle(TitleLibraryPreview)
+	lv.preview.SetDynamicColors(true)
+	lv.preview.SetScrollable(true)
+
+	lv.flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(lv.searchField, 3, 1, true).
+		AddItem(lv.table, 0, 3, false).
+		AddItem(lv.preview, 0, 2, false)
+
+	lv.searchField.SetChangedFunc(lv.filterEntries)
+	lv.searchField.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter, tcell.KeyDown:
+			lv.requestFocus(lv.table)
+			return nil
+		}
+		return event
+	})
+
+	lv.table.SetSelectionChangedFunc(func(row, column int) {
+		lv.updatePreview()
+	})
+	lv.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			lv.selectEntry(false)
+			return nil
+		case tcell.KeyUp:
+			if row, _ := lv.table.GetSelection(); row <= 1 {
+				lv.requestFocus(lv.searchField)
+				return nil
+			}
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'r':
+				lv.selectEntry(true)
+				return nil
+			case 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case 'k':
+				if row, _ := lv.table.GetSelection(); row <= 1 {
+					lv.requestFocus(lv.searchField)
+					return nil
+				}
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			case 'q':
+				if lv.onClose != nil {
+					lv.onClose()
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	return lv
+}
+
+// InitData sets the library entries.
+func (lv *LibraryView) InitData(entries []LibraryEntry) {
+	lv.entries = entries
+	lv.filterEntries(lv.searchField.GetText())
+}
+
+// SetText sets the text the selected entry is previewed against.
+func (lv *LibraryView) SetText(text string) {
+	lv.text = text
+	lv.updatePreview()
+}
+
+// SetOnSelect sets the callback for when an entry is chosen. replace is true if the
+// entry should replace the whole pattern rather than be inserted at the cursor.
+func (lv *LibraryView) SetOnSelect(handler func(pattern string, replace bool)) {
+	lv.onSelect = handler
+}
+
+// SetOnClose sets the callback for when the view is closed without selection.
+func (lv *LibraryView) SetOnClose(handler func()) {
+	lv.onClose = handler
+}
+
+// current returns the selected entry, or false if there is none.
+func (lv *LibraryView) current() (LibraryEntry, bool) {
+	row, _ := lv.table.GetSelection()
+	if row < 1 || row > len(lv.filtered) {
+		return LibraryEntry{}, false
+	}
+	return lv.filtered[row-1], true
+}
+
+func (lv *LibraryView) selectEntry(replace bool) {
+	if entry, ok := lv.current(); ok && lv.onSelect != nil {
+		lv.onSelect(entry.FullPattern(), replace)
+	}
+}
+
+// filterEntries shows the entries whose section, title, pattern, description or tags contain the search text.
+func (lv *LibraryView) filterEntries(searchText string) {
+	lv.table.Clear()
+	lv.filtered = nil
+
+	headers := []string{"", "Section", "Title", "Pattern"}
+	for i, header := range headers {
+		lv.table.SetCell(0, i, tview.NewTableCell(header).SetSelectable(false).SetAlign(tview.AlignCenter).SetBackgroundColor(tcell.ColorDarkBlue))
+	}
+
+	searchText = strings.ToLower(searchText)
+	rowIndex := 1
+	for _, entry := range lv.entries {
+		haystack := strings.ToLower(strings.Join(append([]string{entry.Section, entry.Title, entry.Pattern, entry.Description}, entry.Tags...), "\n"))
+		if searchText != "" && !strings.Contains(haystack, searchText) {
+			continue
+		}
+		lv.filtered = append(lv.filtered, entry)
+		marker, color := "✓", tcell.ColorGreen
+		if _, err := regexp.Compile(entry.FullPattern()); err != nil {
+			marker, color = "✗", tcell.ColorRed
+		}
+		lv.table.SetCell(rowIndex, 0, tview.NewTableCell(marker).SetTextColor(color))
+		lv.table.SetCell(rowIndex, 1, tview.NewTableCell(tview.Escape(entry.Section)).SetExpansion(1))
+		lv.table.SetCell(rowIndex, 2, tview.NewTableCell(tview.Escape(entry.Title)).SetExpansion(2))
+		lv.table.SetCell(rowIndex, 3, tview.NewTableCell(tview.Escape(entry.FullPattern())).SetExpansion(6))
+		rowIndex++
+	}
+	if len(lv.filtered) == 0 {
+		lv.table.SetCell(1, 2, tview.NewTableCell("No patterns found.").SetSelectable(false))
+		lv.table.SetOffset(0, 0)
+	}
+	lv.table.Select(1, 0)
+	lv.updatePreview()
+}
+
+// updatePreview shows the details and compile status of the selected entry and highlights
+// its matches in its sample, if it has one, and in the text.
+func (lv *LibraryView) updatePreview() {
+	entry, ok := lv.current()
+	if !ok {
+		lv.preview.SetText("")
+		return
+	}
+
+	var builder strings.Builder
+	if entry.Description != "" {
+		builder.WriteString(tview.Escape(entry.Description) + "\n")
+	}
+	if len(entry.Tags) > 0 {
+		builder.WriteString("[yellow]Tags:[-] " + tview.Escape(strings.Join(entry.Tags, ", ")) + "\n")
+	}
+	re, err := regexp.Compile(entry.FullPattern())
+	if err != nil {
+		lv.preview.SetTitle(TitleLibraryPreview)
+		builder.WriteString(fmt.Sprintf("[red]Does not compile with RE2: %s[-]", tview.Escape(err.Error())))
+		lv.preview.SetText(builder.String())
+		return
+	}
+	if entry.Sample != "" {
+		builder.WriteString("[yellow]Sample:[-] " + highlightMatches(entry.Sample, re.FindAllStringIndex(entry.Sample, -1)) + "\n")
+	}
+	if builder.Len() > 0 {
+		builder.WriteString("\n")
+	}
+	matches := re.FindAllStringIndex(lv.text, -1)
+	lv.preview.SetTitle(fmt.Sprintf(TitleLibraryPreviewFormat, len(matches)))
+	lv.preview.SetText(builder.String() + highlightMatches(lv.text, matches))
+	lv.preview.ScrollToBeginning()
+}
+
+// Draw implements tview.Primitive.
+func (lv *LibraryView) Draw(screen tcell.Screen) {
+	lv.flex.Draw(screen)
+}
+
+// GetRect implements tview.Primitive.
+func (lv *LibraryView) GetRect() (int, int, int, int) {
+	return lv.flex.GetRect()
+}
+
+// SetRect implements tview.Primitive.
+func (lv *LibraryView) SetRect(x, y, width, height int) {
+	lv.flex.SetRect(x, y, width, height)
+}
+
+// InputHandler returns the handler for this primitive.
+func (lv *LibraryView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return lv.flex.InputHandler()
+}
+
+// Focus is called when this primitive receives focus.
+func (lv *LibraryView) Focus(delegate func(p tview.Primitive)) {
+	delegate(lv.searchField)
+}
+
+// HasFocus returns whether this primitive has focus.
+func (lv *LibraryView) HasFocus() bool {
+	return lv.searchField.HasFocus() || lv.table.HasFocus()
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Generalisation levels of a pattern built from a text selection. Each level keeps the
+// generalisations of the previous ones.
+const (
+	GeneraliseLiteral    = iota // The selection is matched literally.
+	GeneraliseDigits            // Digit runs become `\d+`.
+	GeneraliseWords             // Word runs become `\w+`.
+	GeneraliseWhitespace        // Whitespace runs become `\s+`.
+	GeneraliseLevels            // Number of levels.
+)
+
+// GeneraliseText returns a pattern matching text at the given generalisation level.
+func GeneraliseText(text string, level int) string {
+	if level <= GeneraliseLiteral {
+		return regexp.QuoteMeta(text)
+	}
+	split := splitFine
+	if level >= GeneraliseWords {
+		split = splitCoarse
+	}
+
+	var builder strings.Builder
+	for _, seg := range split(text) {
+		switch {
+		case seg.kind == segDigits || (seg.kind == segWord && isDigits(seg.text)):
+			builder.WriteString(`\d+`)
+		case seg.kind == segWord:
+			builder.WriteString(`\w+`)
+		case seg.kind == segSpace && level >= GeneraliseWhitespace:
+			builder.WriteString(`\s+`)
+		default:
+			builder.WriteString(regexp.QuoteMeta(seg.text))
+		}
+	}
+	return builder.String()
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildPattern returns a pattern for text at the given generalisation level, with each of
+// the groups, given as byte ranges of text, wrapped in a capture group. The groups must not overlap.
+// Each part between group boundaries is generalised on its own, so a group may split a run.
+func BuildPattern(text string, groups []Span, level int) string {
+	sorted := append([]Span(nil), groups...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var builder strings.Builder
+	last := 0
+	for _, g := range sorted {
+		builder.WriteString(GeneraliseText(text[last:g.Start], level))
+		builder.WriteString("(" + GeneraliseText(text[g.Start:g.End], level) + ")")
+		last = g.End
+	}
+	builder.WriteString(GeneraliseText(text[last:], level))
+	return builder.String()
+}
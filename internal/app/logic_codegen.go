@@ -0,0 +1,664 @@
+package app
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Target languages for code generation.
+const (
+	LangGo         = "Go"
+	LangPython     = "Python"
+	LangJavaScript = "JavaScript"
+	LangJava       = "Java"
+	LangRust       = "Rust"
+	LangGrep       = "grep -E / rg"
+	LangSed        = "sed -E"
+)
+
+// CodeLanguages lists the code generation targets in display order.
+var CodeLanguages = []string{LangGo, LangPython, LangJavaScript, LangJava, LangRust, LangGrep, LangSed}
+
+// CodeSnippet is generated source code together with warnings about semantic differences.
+type CodeSnippet struct {
+	Code     string
+	Warnings []string
+}
+
+// codeGenerator turns the RE2 pattern into source code for one target.
+type codeGenerator struct {
+	pattern  string
+	flags    string // Flags set by a leading `(?flags)`.
+	body     string // Pattern without the leading flags.
+	names    []string
+	warnings []string
+	warned   map[string]bool
+	// ereGroups holds the number of each capturing group in the output of toERE, where
+	// every group captures.
+	ereGroups []int
+}
+
+func (g *codeGenerator) warn(msg string) {
+	if g.warned == nil {
+		g.warned = map[string]bool{}
+	}
+	if !g.warned[msg] {
+		g.warned[msg] = true
+		g.warnings = append(g.warnings, msg)
+	}
+}
+
+// GenerateCode emits ready-to-paste code that uses the pattern in the given language.
+func GenerateCode(lang, pattern string) (CodeSnippet, error) {
+	if pattern == "" {
+		return CodeSnippet{}, fmt.Errorf("pattern is empty")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return CodeSnippet{}, err
+	}
+
+	g := &codeGenerator{pattern: pattern, body: pattern, names: re.SubexpNames()}
+	if m := leadingFlagsRe.FindStringSubmatch(pattern); m != nil {
+		g.flags = m[1]
+		g.body = pattern[len(m[0]):]
+	}
+
+	var code string
+	switch lang {
+	case LangGo:
+		code = g.goCode()
+	case LangPython:
+		code, err = g.pythonCode()
+	case LangJavaScript:
+		code, err = g.javaScriptCode()
+	case LangJava:
+		code, err = g.javaCode()
+	case LangRust:
+		code = g.rustCode()
+	case LangGrep:
+		code, err = g.grepCode()
+	case LangSed:
+		code, err = g.sedCode()
+	default:
+		return CodeSnippet{}, fmt.Errorf("unknown language: %s", lang)
+	}
+	if err != nil {
+		return CodeSnippet{}, err
+	}
+	return CodeSnippet{Code: code, Warnings: g.warnings}, nil
+}
+
+// mapTokens rewrites the pattern token by token.
+func mapTokens(pattern string, f func(tok patternToken) string) (string, error) {
+	tokens, err := tokenizePattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	for _, tok := range tokens {
+		builder.WriteString(f(tok))
+	}
+	return builder.String(), nil
+}
+
+// posixRanges spells POSIX classes as plain ranges for engines that don't know them.
+var posixRanges = map[string]string{
+	"alnum": `0-9A-Za-z`, "alpha": `A-Za-z`, "ascii": `\x00-\x7F`, "blank": `\t `,
+	"cntrl": `\x00-\x1F\x7F`, "digit": `0-9`, "graph": `!-~`, "lower": `a-z`,
+	"print": ` -~`, "punct": `!-/:-@\[-` + "`" + `{-~`, "space": `\t\n\v\f\r `,
+	"upper": `A-Z`, "word": `0-9A-Za-z_`, "xdigit": `0-9A-Fa-f`,
+}
+
+var posixClassRe = regexp.MustCompile(`\[:(\^?)([a-z]+):\]`)
+
+// expandPosixClasses replaces `[:name:]` inside a class by explicit ranges.
+func (g *codeGenerator) expandPosixClasses(class string) string {
+	return posixClassRe.ReplaceAllStringFunc(class, func(m string) string {
+		parts := posixClassRe.FindStringSubmatch(m)
+		if parts[1] != "" {
+			g.warn(fmt.Sprintf("negated POSIX class %s cannot be expanded and was kept", m))
+			return m
+		}
+		return posixRanges[parts[2]]
+	})
+}
+
+// hasToken reports whether any token of the pattern body satisfies the predicate.
+func (g *codeGenerator) hasToken(pred func(tok patternToken) bool) bool {
+	tokens, _ := tokenizePattern(g.body)
+	for _, tok := range tokens {
+		if pred(tok) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAnchors warns that `$` is looser in engines where it also matches before a final newline.
+func (g *codeGenerator) checkAnchors(lang string) {
+	if !strings.Contains(g.flags, "m") && g.hasToken(func(tok patternToken) bool { return tok.Text == "$" && tok.Kind == tokAnchor }) {
+		g.warn(fmt.Sprintf("$ also matches before a trailing newline in %s; in Go it only matches at the very end", lang))
+	}
+}
+
+// checkUnicodeClasses warns about Perl classes that are ASCII-only in Go but Unicode-aware in the target.
+func (g *codeGenerator) checkUnicodeClasses(lang string) {
+	if g.hasToken(func(tok patternToken) bool {
+		return tok.Kind == tokEscape && strings.ContainsRune("wWdDsSbB", rune(tok.Text[1]))
+	}) {
+		g.warn(fmt.Sprintf(`\w, \d, \s and \b are Unicode-aware in %s but ASCII-only in Go`, lang))
+	}
+}
+
+// isUngreedyFlag reports whether the token sets the U flag, e.g. `(?U)` or `(?U:`.
+func isUngreedyFlag(tok patternToken) bool {
+	return (tok.Kind == tokFlags || tok.Kind == tokGroupOpen && !tok.Capturing) && strings.Contains(tok.Text, "U")
+}
+
+// identifier turns a group name into a variable name that is valid in most languages.
+func identifier(name string) string {
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "g" + name
+	}
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+func (g *codeGenerator) namedGroups() []string {
+	var names []string
+	for _, name := range g.names {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// goReserved are the names used by the code of goCode, which group variables must not shadow.
+var goReserved = map[string]bool{"_": true, "fmt": true, "m": true, "re": true, "text": true}
+
+// goVariables maps the group names to distinct Go variable names, adding underscores to
+// names that are reserved or already taken: m becomes m_.
+func (g *codeGenerator) goVariables() map[string]string {
+	variables := map[string]string{}
+	used := map[string]bool{}
+	for _, name := range g.namedGroups() {
+		if identifier(name) == name && !goReserved[name] {
+			variables[name] = name
+			used[name] = true
+		}
+	}
+	for _, name := range g.namedGroups() {
+		if _, ok := variables[name]; ok {
+			continue
+		}
+		v := identifier(name)
+		for goReserved[v] || used[v] {
+			v += "_"
+		}
+		variables[name] = v
+		used[v] = true
+	}
+	return variables
+}
+
+func (g *codeGenerator) goCode() string {
+	literal := "`" + g.pattern + "`"
+	if strings.ContainsAny(g.pattern, "`\r") {
+		literal = strconv.Quote(g.pattern)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("re := regexp.MustCompile(" + literal + ")\n")
+	builder.WriteString("for _, m := range re.FindAllStringSubmatch(text, -1) {\n")
+	args := []string{"m[0]"}
+	variables := g.goVariables()
+	for _, name := range g.namedGroups() {
+		v := variables[name]
+		fmt.Fprintf(&builder, "\t%s := m[re.SubexpIndex(%q)]\n", v, name)
+		args = append(args, v)
+	}
+	if len(args) == 1 && len(g.names) > 1 {
+		args = []string{"m"}
+	}
+	fmt.Fprintf(&builder, "\tfmt.Println(%s)\n}", strings.Join(args, ", "))
+	return builder.String()
+}
+
+var pythonFlagNames = map[rune]string{'i': "re.IGNORECASE", 'm': "re.MULTILINE", 's': "re.DOTALL"}
+
+func (g *codeGenerator) pythonCode() (string, error) {
+	body, err := mapTokens(g.body, func(tok patternToken) string {
+		switch {
+		case tok.Text == `\z`:
+			return `\Z`
+		case tok.Kind == tokClass:
+			return pythonHexEscapes(g.expandPosixClasses(tok.Text))
+		case tok.Kind == tokLiteral:
+			return pythonHexEscapes(tok.Text)
+		case tok.Kind == tokEscape && (tok.Text[1] == 'p' || tok.Text[1] == 'P'):
+			g.warn(fmt.Sprintf("%s requires the third-party regex module; the re module does not support Unicode properties", tok.Text))
+		case isUngreedyFlag(tok):
+			g.warn("the U (ungreedy) flag does not exist in Python")
+		}
+		return tok.Text
+	})
+	if err != nil {
+		return "", err
+	}
+	g.checkAnchors(LangPython)
+	g.checkUnicodeClasses(LangPython)
+
+	var flags []string
+	for _, f := range g.flags {
+		if name, ok := pythonFlagNames[f]; ok {
+			flags = append(flags, name)
+		} else {
+			g.warn(fmt.Sprintf("the %c flag does not exist in Python", f))
+		}
+	}
+	args := pythonString(body)
+	if len(flags) > 0 {
+		args += ", " + strings.Join(flags, " | ")
+	}
+
+	var builder strings.Builder
+	builder.WriteString("import re\n\n")
+	builder.WriteString("pattern = re.compile(" + args + ")\n")
+	builder.WriteString("for m in pattern.finditer(text):\n")
+	groups := []string{"m.group(0)"}
+	for _, name := range g.namedGroups() {
+		groups = append(groups, fmt.Sprintf("m.group(%q)", name))
+	}
+	builder.WriteString("    print(" + strings.Join(groups, ", ") + ")")
+	return builder.String(), nil
+}
+
+var hexEscapeRe = regexp.MustCompile(`\\(?:\\|x\{([0-9A-Fa-f]+)\})`)
+
+// pythonHexEscapes rewrites `\x{H...}`, which Python doesn't know, as `\xHH`, `\uHHHH` or
+// `\UHHHHHHHH`.
+func pythonHexEscapes(s string) string {
+	return hexEscapeRe.ReplaceAllStringFunc(s, func(m string) string {
+		if m == `\\` {
+			return m
+		}
+		code, _ := strconv.ParseUint(hexEscapeRe.FindStringSubmatch(m)[1], 16, 32)
+		switch {
+		case code <= 0xFF:
+			return fmt.Sprintf(`\x%02x`, code)
+		case code <= 0xFFFF:
+			return fmt.Sprintf(`\u%04x`, code)
+		}
+		return fmt.Sprintf(`\U%08x`, code)
+	})
+}
+
+// pythonString quotes the pattern as a raw string when possible.
+func pythonString(s string) string {
+	trailing := len(s) - len(strings.TrimRight(s, `\`))
+	if !strings.ContainsAny(s, "\n\r") && trailing%2 == 0 {
+		switch {
+		case !strings.Contains(s, "'"):
+			return "r'" + s + "'"
+		case !strings.Contains(s, `"`):
+			return `r"` + s + `"`
+		}
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`, "\n", `\n`, "\r", `\r`).Replace(s) + "'"
+}
+
+func (g *codeGenerator) javaScriptCode() (string, error) {
+	unicode := false
+	body, err := mapTokens(g.body, func(tok patternToken) string {
+		switch {
+		case tok.Kind == tokGroupOpen && tok.Name != "":
+			return "(?<" + tok.Name + ">"
+		case tok.Kind == tokGroupOpen && tok.Text != "(" && tok.Text != "(?:":
+			g.warn(fmt.Sprintf("JavaScript has no scoped flags like %s", tok.Text))
+		case tok.Kind == tokFlags:
+			g.warn(fmt.Sprintf("JavaScript has no inline flags like %s", tok.Text))
+		case tok.Text == `\A`:
+			if strings.Contains(g.flags, "m") {
+				g.warn(`\A has no equivalent with the m flag; ^ was used`)
+			}
+			return "^"
+		case tok.Text == `\z`:
+			if strings.Contains(g.flags, "m") {
+				g.warn(`\z has no equivalent with the m flag; $ was used`)
+			}
+			return "$"
+		case tok.Kind == tokEscape && (tok.Text[1] == 'p' || tok.Text[1] == 'P'):
+			unicode = true
+			name := strings.Trim(tok.Text[2:], "{}")
+			if len(name) > 2 {
+				name = "Script=" + name
+			}
+			return tok.Text[:2] + "{" + name + "}"
+		case tok.Kind == tokClass:
+			return g.expandPosixClasses(tok.Text)
+		case tok.Text == "/":
+			return `\/`
+		case tok.Kind == tokLiteral && strings.HasPrefix(tok.Text, `\x{`):
+			unicode = true
+			return `\u{` + tok.Text[3:]
+		}
+		return tok.Text
+	})
+	if err != nil {
+		return "", err
+	}
+
+	flags := "g"
+	for _, f := range g.flags {
+		if strings.ContainsRune("ims", f) {
+			flags += string(f)
+		} else {
+			g.warn(fmt.Sprintf("the %c flag does not exist in JavaScript", f))
+		}
+	}
+	if unicode {
+		flags += "u"
+	}
+	if !strings.Contains(g.flags, "s") && g.hasToken(func(tok patternToken) bool { return tok.Kind == tokDot }) {
+		g.warn(`. also excludes \r, U+2028 and U+2029 in JavaScript`)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("const re = /" + body + "/" + flags + ";\n")
+	builder.WriteString("for (const m of text.matchAll(re)) {\n")
+	groups := []string{"m[0]"}
+	for _, name := range g.namedGroups() {
+		groups = append(groups, "m.groups."+name)
+	}
+	builder.WriteString("  console.log(" + strings.Join(groups, ", ") + ");\n}")
+	return builder.String(), nil
+}
+
+// javaGroupNames maps the group names to names that are valid in Java, which only allows
+// ASCII letters and digits, starting with a letter: user_id becomes userId.
+func (g *codeGenerator) javaGroupNames() map[string]string {
+	renamed := map[string]string{}
+	used := map[string]bool{}
+	for _, name := range g.namedGroups() {
+		if javaGroupNameRe.MatchString(name) {
+			used[name] = true
+		}
+	}
+	for _, name := range g.namedGroups() {
+		if javaGroupNameRe.MatchString(name) {
+			renamed[name] = name
+			continue
+		}
+		var builder strings.Builder
+		upper := false
+		for _, r := range name {
+			switch {
+			case r == '_':
+				upper = builder.Len() > 0
+			case upper:
+				builder.WriteString(strings.ToUpper(string(r)))
+				upper = false
+			default:
+				builder.WriteRune(r)
+			}
+		}
+		base := builder.String()
+		if base == "" || base[0] >= '0' && base[0] <= '9' {
+			base = "g" + base
+		}
+		java := base
+		for i := 2; used[java]; i++ {
+			java = base + strconv.Itoa(i)
+		}
+		used[java] = true
+		renamed[name] = java
+		g.warn(fmt.Sprintf("group name %s is not valid in Java and was renamed to %s", name, java))
+	}
+	return renamed
+}
+
+var javaGroupNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// javaString quotes s as a Java string literal. Non-printable characters are written as
+// \uXXXX, except line terminators, which Java translates before parsing the literal.
+func javaString(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\f':
+			builder.WriteString(`\f`)
+		default:
+			if unicode.IsPrint(r) {
+				builder.WriteRune(r)
+			} else {
+				for _, unit := range utf16.Encode([]rune{r}) {
+					fmt.Fprintf(&builder, `\u%04x`, unit)
+				}
+			}
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+func (g *codeGenerator) javaCode() (string, error) {
+	names := g.javaGroupNames()
+	body, err := mapTokens(g.pattern, func(tok patternToken) string {
+		switch {
+		case tok.Kind == tokGroupOpen && tok.Name != "":
+			return "(?<" + names[tok.Name] + ">"
+		case isUngreedyFlag(tok):
+			g.warn("(?U) means UNICODE_CHARACTER_CLASS in Java, not ungreedy")
+		case tok.Kind == tokEscape && len(tok.Text) > 4 && (tok.Text[1] == 'p' || tok.Text[1] == 'P'):
+			return tok.Text[:3] + "Is" + tok.Text[3:]
+		case tok.Kind == tokClass:
+			return posixClassRe.ReplaceAllStringFunc(tok.Text, func(m string) string {
+				parts := posixClassRe.FindStringSubmatch(m)
+				name := strings.ToUpper(parts[2][:1]) + parts[2][1:]
+				if parts[2] == "xdigit" {
+					name = "XDigit"
+				}
+				if parts[1] != "" {
+					return `\P{` + name + `}`
+				}
+				return `\p{` + name + `}`
+			})
+		}
+		return tok.Text
+	})
+	if err != nil {
+		return "", err
+	}
+	g.checkAnchors(LangJava)
+
+	var builder strings.Builder
+	builder.WriteString("Pattern pattern = Pattern.compile(" + javaString(body) + ");\n")
+	builder.WriteString("Matcher m = pattern.matcher(text);\n")
+	builder.WriteString("while (m.find()) {\n")
+	groups := []string{"m.group()"}
+	for _, name := range g.namedGroups() {
+		groups = append(groups, fmt.Sprintf("m.group(%q)", names[name]))
+	}
+	builder.WriteString("    System.out.println(" + strings.Join(groups, ` + " " + `) + ");\n}")
+	return builder.String(), nil
+}
+
+func (g *codeGenerator) rustCode() string {
+	hashes := ""
+	for strings.Contains(g.pattern, `"`+hashes) {
+		hashes += "#"
+	}
+	g.checkUnicodeClasses(LangRust)
+
+	var builder strings.Builder
+	builder.WriteString("let re = Regex::new(r" + hashes + `"` + g.pattern + `"` + hashes + ").unwrap();\n")
+	builder.WriteString("for caps in re.captures_iter(text) {\n")
+	format := []string{"{}"}
+	args := []string{"&caps[0]"}
+	for _, name := range g.namedGroups() {
+		format = append(format, "{}")
+		args = append(args, fmt.Sprintf("&caps[%q]", name))
+	}
+	fmt.Fprintf(&builder, "    println!(%q, %s);\n}", strings.Join(format, " "), strings.Join(args, ", "))
+	return builder.String()
+}
+
+// ereEscapes spells Perl classes in POSIX ERE.
+var ereEscapes = map[string][2]string{
+	`\d`: {`[0-9]`, `0-9`},
+	`\D`: {`[^0-9]`, ""},
+	`\w`: {`[[:alnum:]_]`, `[:alnum:]_`},
+	`\W`: {`[^[:alnum:]_]`, ""},
+	`\s`: {`[[:space:]]`, `[:space:]`},
+	`\S`: {`[^[:space:]]`, ""},
+}
+
+var classEscapeRe = regexp.MustCompile(`\\.`)
+
+// toERE rewrites the pattern body as a POSIX extended regular expression.
+func (g *codeGenerator) toERE() (string, error) {
+	g.ereGroups = nil
+	groups := 0
+	return mapTokens(g.body, func(tok patternToken) string {
+		switch tok.Kind {
+		case tokGroupOpen:
+			groups++
+			if tok.Capturing {
+				g.ereGroups = append(g.ereGroups, groups)
+			}
+			if tok.Text != "(" {
+				if tok.Text != "(?:" && tok.Name == "" {
+					g.warn(fmt.Sprintf("ERE has no scoped flags like %s; they were dropped", tok.Text))
+				}
+				if tok.Name != "" {
+					g.warn("ERE has no named groups; they were turned into numbered groups")
+				}
+				if tok.Text == "(?:" {
+					g.warn("ERE has no non-capturing groups; they were made capturing")
+				}
+			}
+			return "("
+		case tokFlags:
+			g.warn(fmt.Sprintf("ERE has no inline flags like %s; they were dropped", tok.Text))
+			return ""
+		case tokQuant:
+			if len(tok.Text) > 1 && strings.HasSuffix(tok.Text, "?") {
+				g.warn("ERE has no lazy quantifiers; they were made greedy")
+				return strings.TrimSuffix(tok.Text, "?")
+			}
+		case tokEscape:
+			if e, ok := ereEscapes[tok.Text]; ok {
+				return e[0]
+			}
+			switch tok.Text {
+			case `\A`:
+				return "^"
+			case `\z`:
+				return "$"
+			case `\b`, `\B`:
+				g.warn(`\b and \B are GNU extensions`)
+			default:
+				g.warn(fmt.Sprintf("%s has no ERE equivalent and was kept", tok.Text))
+			}
+		case tokClass:
+			// Backslashes are literal inside ERE brackets.
+			return classEscapeRe.ReplaceAllStringFunc(tok.Text, func(esc string) string {
+				if e, ok := ereEscapes[esc]; ok && e[1] != "" {
+					return e[1]
+				}
+				c := esc[1]
+				if c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || strings.IndexByte(`]^-\`, c) >= 0 {
+					g.warn(fmt.Sprintf("%s inside a bracket expression has no ERE equivalent and was kept", esc))
+					return esc
+				}
+				return esc[1:]
+			})
+		}
+		return tok.Text
+	})
+}
+
+// shellQuote wraps s in single quotes for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellFlags returns command line options for the leading flags and warns about the rest.
+func (g *codeGenerator) shellFlags(tool string) string {
+	options := ""
+	for _, f := range g.flags {
+		if f == 'i' {
+			options += " -i"
+		} else {
+			g.warn(fmt.Sprintf("the %c flag has no %s option and was dropped", f, tool))
+		}
+	}
+	return options
+}
+
+func (g *codeGenerator) grepCode() (string, error) {
+	ere, err := g.toERE()
+	if err != nil {
+		return "", err
+	}
+	options := g.shellFlags("grep")
+	var builder strings.Builder
+	builder.WriteString("grep -Eo" + options + " " + shellQuote(ere) + " file\n")
+	// ripgrep uses Rust regex syntax, which accepts the Go pattern as it is.
+	builder.WriteString("rg -o " + shellQuote(g.pattern) + " file")
+	return builder.String(), nil
+}
+
+func (g *codeGenerator) sedCode() (string, error) {
+	ere, err := g.toERE()
+	if err != nil {
+		return "", err
+	}
+	ere = strings.ReplaceAll(ere, "/", `\/`)
+	modifiers := "g"
+	for _, f := range g.flags {
+		if f == 'i' {
+			// GNU extension.
+			modifiers += "I"
+		} else {
+			g.warn(fmt.Sprintf("the %c flag has no sed modifier and was dropped", f))
+		}
+	}
+	replacement := "<&>"
+	if len(g.ereGroups) > 0 {
+		refs := make([]string, 0, len(g.ereGroups))
+		for _, n := range g.ereGroups {
+			if n > 9 {
+				g.warn(`sed can only refer to groups \1 to \9`)
+				break
+			}
+			refs = append(refs, `\`+strconv.Itoa(n))
+		}
+		if len(refs) > 0 {
+			replacement = strings.Join(refs, " ")
+		}
+	}
+	return "sed -E " + shellQuote("s/"+ere+"/"+replacement+"/"+modifiers) + " file", nil
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Completion is a suggestion for the partial text before the cursor.
+type Completion struct {
+	Display string // Shown in the drop-down.
+	Insert  string // Replaces the partial text.
+}
+
+var (
+	unicodeNameRe  = regexp.MustCompile(`\\[pP]\{([A-Za-z_]*)$`)
+	posixNameRe    = regexp.MustCompile(`\[[^\[\]]*(\[:\^?([a-z]*))$`)
+	groupFlagsRe   = regexp.MustCompile(`\(\?([a-zA-Z]*)$`)
+	formatGroupRe  = regexp.MustCompile(`\$(\d*)$`)
+	unicodeClasses = unicodeClassNames()
+)
+
+// groupSyntaxes are offered after `(?`.
+var groupSyntaxes = []Completion{
+	{"(?:    non-capturing group", "(?:"},
+	{"(?P<   named group", "(?P<"},
+	{"(?i)   case-insensitive", "(?i)"},
+	{"(?m)   multi-line ^ and $", "(?m)"},
+	{"(?s)   . matches \\n", "(?s)"},
+	{"(?U)   ungreedy", "(?U)"},
+	{"(?i:   case-insensitive group", "(?i:"},
+	{"(?s:   group where . matches \\n", "(?s:"},
+}
+
+// unicodeClassNames returns the names accepted by \p{...}: Any, the categories and the scripts.
+func unicodeClassNames() []string {
+	names := []string{"Any"}
+	names = append(names, sortedKeys(unicode.Categories)...)
+	return append(names, sortedKeys(unicode.Scripts)...)
+}
+
+// CompletePattern returns the completions for a pattern given the text before the cursor,
+// and the offset in that text where the partial text to replace starts.
+func CompletePattern(before string) (int, []Completion) {
+	if m := unicodeNameRe.FindStringSubmatchIndex(before); m != nil {
+		partial := strings.ToLower(before[m[2]:m[3]])
+		escape := before[m[0] : m[0]+2]
+		var completions []Completion
+		for _, name := range unicodeClasses {
+			if strings.HasPrefix(strings.ToLower(name), partial) {
+				insert := escape + "{" + name + "}"
+				completions = append(completions, Completion{insert, insert})
+			}
+		}
+		return m[0], completions
+	}
+
+	if m := posixNameRe.FindStringSubmatchIndex(before); m != nil {
+		negate := strings.Contains(before[m[2]:m[4]], "^")
+		partial := before[m[4]:m[5]]
+		var completions []Completion
+		for _, class := range posixClasses {
+			if strings.HasPrefix(class[0], partial) {
+				insert := "[:" + class[0] + ":]"
+				if negate {
+					insert = "[:^" + class[0] + ":]"
+				}
+				completions = append(completions, Completion{fmt.Sprintf("%-12s %s", insert, class[1]), insert})
+			}
+		}
+		return m[2], completions
+	}
+
+	if m := groupFlagsRe.FindStringSubmatchIndex(before); m != nil && !strings.HasSuffix(before[:m[0]], `\`) {
+		typed := before[m[0]:]
+		var completions []Completion
+		for _, c := range groupSyntaxes {
+			if strings.HasPrefix(c.Insert, typed) && c.Insert != typed {
+				completions = append(completions, c)
+			}
+		}
+		return m[0], completions
+	}
+	return len(before), nil
+}
+
+// CompleteFormat returns the group references of the pattern that complete the text
+// before the cursor of a format string, e.g. `$1` after `$`.
+func CompleteFormat(before, pattern string) (int, []Completion) {
+	m := formatGroupRe.FindStringSubmatchIndex(before)
+	if m == nil {
+		return len(before), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return len(before), nil
+	}
+
+	partial := before[m[2]:m[3]]
+	var completions []Completion
+	for i, name := range re.SubexpNames() {
+		number := strconv.Itoa(i)
+		if !strings.HasPrefix(number, partial) {
+			continue
+		}
+		display := "$" + number
+		switch {
+		case i == 0:
+			display += "  whole match"
+		case name != "":
+			display += "  " + name
+		}
+		completions = append(completions, Completion{display, "$" + number})
+	}
+	return m[0], completions
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// HeaderMode selects the header row of the CSV and TSV exports.
+type HeaderMode int
+
+// Header modes, in the order of the export form.
+const (
+	HeaderNames   HeaderMode = iota // Group names, numbers for unnamed groups.
+	HeaderNumbers                   // Group numbers.
+	HeaderNone                      // No header row.
+)
+
+// HeaderModeNames are the names of the header modes on the command line, in the order of HeaderMode.
+var HeaderModeNames = []string{"names", "numbers", "none"}
+
+// groupHeaders returns the name of each group of the pattern, or its number if it has none.
+func groupHeaders(re *regexp.Regexp) []string {
+	headers := slices.Clone(re.SubexpNames())
+	for i, name := range headers {
+		if name == "" {
+			headers[i] = strconv.Itoa(i)
+		}
+	}
+	return headers
+}
+
+// GenerateExportCSV generates a row per match with a column per group, separated by comma,
+// e.g. ',' for CSV or '\t' for TSV. If groupInput is set, only these groups are exported, in
+// its order.
+func GenerateExportCSV(regexStr string, matches [][]string, groupInput string, header HeaderMode, comma rune) ([]byte, error) {
+	re, err := regexp.Compile(regexStr)
+	if err != nil {
+		return nil, err
+	}
+	var groups []int
+	if groupInput == "" {
+		for g := 0; g <= re.NumSubexp(); g++ {
+			groups = append(groups, g)
+		}
+	} else {
+		if groups, err = parseGroupNumbers(groupInput); err != nil {
+			return nil, err
+		}
+		for _, g := range groups {
+			if g < 0 || g > re.NumSubexp() {
+				return nil, fmt.Errorf("the pattern has no group %d", g)
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writer.Comma = comma
+	row := make([]string, len(groups))
+	if header != HeaderNone {
+		names := groupHeaders(re)
+		for i, g := range groups {
+			row[i] = strconv.Itoa(g)
+			if header == HeaderNames {
+				row[i] = names[g]
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	for _, match := range matches {
+		for i, g := range groups {
+			row[i] = match[g]
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
@@ -0,0 +1,571 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Source dialects that can be translated to Go RE2 syntax.
+const (
+	DialectPCRE       = "PCRE"
+	DialectJavaScript = "JavaScript"
+	DialectPython     = "Python"
+	DialectJava       = "Java"
+)
+
+// Dialects lists the supported source dialects in display order.
+var Dialects = []string{DialectPCRE, DialectJavaScript, DialectPython, DialectJava}
+
+// TranslateResult is the outcome of translating a foreign pattern into RE2 syntax.
+type TranslateResult struct {
+	Pattern string
+	// Notes describe translations that changed the spelling or slightly changed the behaviour.
+	Notes []string
+	// Unsupported lists constructs RE2 cannot express. They are left in the pattern as they were.
+	Unsupported []string
+}
+
+// Report renders the notes and unsupported constructs for display.
+func (r TranslateResult) Report() string {
+	var builder strings.Builder
+	if len(r.Unsupported) > 0 {
+		builder.WriteString("Cannot be translated:\n")
+		for _, u := range r.Unsupported {
+			builder.WriteString("  - " + u + "\n")
+		}
+	}
+	if len(r.Notes) > 0 {
+		builder.WriteString("Notes:\n")
+		for _, n := range r.Notes {
+			builder.WriteString("  - " + n + "\n")
+		}
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// TranslateToRE2 translates a pattern written for another regex dialect into Go RE2 syntax.
+// The input may be a bare pattern or the way the pattern is usually written in that language:
+// `/.../flags` for JavaScript and PCRE, or string literals (optionally with flag constants) for Python and Java.
+func TranslateToRE2(dialect, input string) (TranslateResult, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return TranslateResult{}, fmt.Errorf("pattern is empty")
+	}
+
+	t := &translator{dialect: dialect}
+	var body string
+	var flags []byte
+	switch dialect {
+	case DialectJavaScript, DialectPCRE:
+		body, flags = splitDelimited(dialect, input)
+	case DialectPython, DialectJava:
+		body, flags = t.unwrapStringLiterals(input)
+	default:
+		return TranslateResult{}, fmt.Errorf("unknown dialect: %s", dialect)
+	}
+
+	// A leading inline (?x) is treated like the verbose flag.
+	if m := leadingFlagsRe.FindStringSubmatch(body); m != nil && strings.Contains(m[1], "x") {
+		body = body[len(m[0]):]
+		flags = append(flags, []byte(m[1])...)
+	}
+
+	var goFlags []byte
+	for _, f := range flags {
+		switch f {
+		case 'i', 'm', 's':
+			goFlags = appendFlag(goFlags, f)
+		case 'U':
+			if dialect == DialectPCRE {
+				goFlags = appendFlag(goFlags, 'U')
+			}
+		case 'x':
+			if !t.verbose {
+				t.verbose = true
+				body = stripVerbose(body)
+				t.note("verbose mode: whitespace and # comments were removed")
+			}
+		case 'g':
+			t.note("flag g ignored: all matches are always found")
+		case 'u', 'v':
+			t.note(fmt.Sprintf("flag %c ignored: RE2 always works on Unicode text", f))
+		case 'y':
+			t.note("flag y (sticky) ignored: there is no lastIndex in RE2")
+		case 'd':
+			t.note("flag d ignored: match indices are always available")
+		default:
+			t.note(fmt.Sprintf("flag %c has no RE2 equivalent and was ignored", f))
+		}
+	}
+	t.multiline = strings.ContainsRune(string(goFlags), 'm')
+
+	translated := t.translate(body)
+	if len(goFlags) > 0 {
+		translated = "(?" + string(goFlags) + ")" + translated
+	}
+	return TranslateResult{Pattern: translated, Notes: t.notes, Unsupported: t.unsupported}, nil
+}
+
+var leadingFlagsRe = regexp.MustCompile(`^\(\?([a-zA-Z]+)\)`)
+
+func appendFlag(flags []byte, f byte) []byte {
+	if strings.IndexByte(string(flags), f) >= 0 {
+		return flags
+	}
+	return append(flags, f)
+}
+
+// splitDelimited separates `/body/flags` style input. Input without delimiters is returned unchanged.
+func splitDelimited(dialect, input string) (string, []byte) {
+	delimiters := "/"
+	if dialect == DialectPCRE {
+		delimiters = "/#~!@%;"
+	}
+	if len(input) < 2 || !strings.ContainsRune(delimiters, rune(input[0])) {
+		return input, nil
+	}
+	end := strings.LastIndexByte(input, input[0])
+	if end == 0 {
+		return input, nil
+	}
+	flags := input[end+1:]
+	for _, f := range flags {
+		if !('a' <= f && f <= 'z' || 'A' <= f && f <= 'Z') {
+			return input, nil
+		}
+	}
+	body := strings.ReplaceAll(input[1:end], `\`+string(input[0]), string(input[0]))
+	return body, []byte(flags)
+}
+
+var (
+	pythonLiteralRe = regexp.MustCompile(`(?s)([rRbBuU]{0,2})("""(.*?)"""|'''(.*?)'''|"((?:[^"\\]|\\.)*)"|'((?:[^'\\]|\\.)*)')`)
+	javaLiteralRe   = regexp.MustCompile(`(?s)()("""\s*\n(.*?)"""|"((?:[^"\\]|\\.)*)")`)
+	pythonFlags     = map[string]byte{"I": 'i', "IGNORECASE": 'i', "M": 'm', "MULTILINE": 'm', "S": 's', "DOTALL": 's', "X": 'x', "VERBOSE": 'x', "A": 'a', "ASCII": 'a', "U": 'u', "UNICODE": 'u', "L": 'L', "LOCALE": 'L'}
+	javaFlags       = map[string]byte{"CASE_INSENSITIVE": 'i', "MULTILINE": 'm', "DOTALL": 's', "COMMENTS": 'x', "UNICODE_CASE": 'u', "UNICODE_CHARACTER_CLASS": 'u', "LITERAL": 'q', "UNIX_LINES": 'd', "CANON_EQ": 'c'}
+	flagNameRe      = regexp.MustCompile(`\b(?:re|Pattern)\.([A-Z_]+)\b`)
+)
+
+// unwrapStringLiterals extracts and concatenates the string literals of Python or Java source
+// (e.g. `re.compile(r"\d+", re.I)` or `Pattern.compile("\\d+" + "x")`) and collects the flag constants.
+// Input without string literals is returned unchanged.
+func (t *translator) unwrapStringLiterals(input string) (string, []byte) {
+	literalRe, flagNames := pythonLiteralRe, pythonFlags
+	if t.dialect == DialectJava {
+		literalRe, flagNames = javaLiteralRe, javaFlags
+	}
+	found := literalRe.FindAllStringSubmatchIndex(input, -1)
+	if len(found) == 0 {
+		return input, nil
+	}
+
+	var body strings.Builder
+	for _, loc := range found {
+		prefix := strings.ToLower(input[loc[2]:loc[3]])
+		var content string
+		for g := 3; g*2+1 < len(loc); g++ {
+			if loc[g*2] >= 0 {
+				content = input[loc[g*2]:loc[g*2+1]]
+				break
+			}
+		}
+		if strings.Contains(prefix, "r") {
+			body.WriteString(content)
+		} else {
+			body.WriteString(unescapeStringLiteral(content))
+		}
+	}
+
+	rest := literalRe.ReplaceAllString(input, "")
+	var flags []byte
+	for _, m := range flagNameRe.FindAllStringSubmatch(rest, -1) {
+		f, ok := flagNames[m[1]]
+		if !ok {
+			continue
+		}
+		switch f {
+		case 'q':
+			t.note("LITERAL flag: the pattern was quoted with \\Q...\\E")
+			return `\Q` + body.String() + `\E`, flags
+		case 'a', 'L', 'd', 'c':
+			t.note(fmt.Sprintf("flag %s has no RE2 equivalent and was ignored", m[1]))
+			continue
+		}
+		flags = append(flags, f)
+	}
+	return body.String(), flags
+}
+
+// unescapeStringLiteral undoes the string-level escaping of a non-raw literal. Escapes the regex
+// engine also understands (like `\n` or `\d`) are kept so that the pattern stays readable.
+func unescapeStringLiteral(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case '\\', '"', '\'':
+				builder.WriteByte(s[i+1])
+				i++
+				continue
+			}
+		}
+		builder.WriteByte(s[i])
+	}
+	return builder.String()
+}
+
+// stripVerbose removes the whitespace and `#` comments that verbose mode ignores.
+func stripVerbose(body string) string {
+	var builder strings.Builder
+	inClass := false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\\' && i+1 < len(body):
+			builder.WriteString(body[i : i+2])
+			i++
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+			builder.WriteByte(c)
+		case c == '[':
+			inClass = true
+			builder.WriteByte(c)
+			// A ']' directly after '[' or '[^' is a literal.
+			if i+1 < len(body) && body[i+1] == '^' {
+				builder.WriteByte('^')
+				i++
+			}
+			if i+1 < len(body) && body[i+1] == ']' {
+				builder.WriteByte(']')
+				i++
+			}
+		case c == '#':
+			for i < len(body) && body[i] != '\n' {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+// translator rewrites the body of a pattern token by token.
+type translator struct {
+	dialect     string
+	verbose     bool
+	multiline   bool
+	notes       []string
+	unsupported []string
+	noted       map[string]bool
+}
+
+func (t *translator) note(msg string) {
+	if t.noted == nil {
+		t.noted = map[string]bool{}
+	}
+	if !t.noted[msg] {
+		t.noted[msg] = true
+		t.notes = append(t.notes, msg)
+	}
+}
+
+func (t *translator) fail(construct, what string) {
+	t.unsupported = append(t.unsupported, fmt.Sprintf("%s %s", what, construct))
+}
+
+var (
+	javaPosixClasses = map[string]string{
+		"Lower": "lower", "Upper": "upper", "ASCII": "ascii", "Alpha": "alpha", "Digit": "digit",
+		"Alnum": "alnum", "Punct": "punct", "Graph": "graph", "Print": "print", "Blank": "blank",
+		"Cntrl": "cntrl", "XDigit": "xdigit", "Space": "space",
+	}
+	unicodePropertyRe = regexp.MustCompile(`^(?:Script|sc|Script_Extensions|scx|General_Category|gc)=`)
+	quantifierEndRe   = regexp.MustCompile(`^(?:[*+?]|\{\d*(?:,\d*)?\})$`)
+	pcreBackrefRe     = regexp.MustCompile(`^\\g(?:\{[^}]*\}|<[^>]*>|'[^']*'|-?\d+)`)
+	groupSpecialRe    = regexp.MustCompile(`^\(\?(?:<=|<!|=|!|>|\||R\)|[+-]?\d+\)|&|P>|P=|\(|C\d*\)|#)`)
+)
+
+func (t *translator) translate(body string) string {
+	var out strings.Builder
+	inClass := false
+	classStart := 0
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(body) {
+				out.WriteByte(c)
+				continue
+			}
+			n := t.translateEscape(&out, body[i:], inClass)
+			i += n - 1
+		case inClass:
+			switch {
+			case c == ']' && i > classStart:
+				inClass = false
+			case c == '&' && strings.HasPrefix(body[i:], "&&") && t.dialect == DialectJava:
+				t.fail("&&", "class intersection")
+			case c == '[' && t.dialect == DialectJava && !strings.HasPrefix(body[i:], "[:"):
+				t.fail("[...[...]]", "nested class union")
+			}
+			out.WriteByte(c)
+		case c == '[':
+			if t.dialect == DialectJavaScript && strings.HasPrefix(body[i:], "[^]") {
+				out.WriteString(`(?s:.)`)
+				i += 2
+				continue
+			}
+			inClass = true
+			out.WriteByte(c)
+			// The class body starts after an optional '^'; a ']' right there is a literal.
+			if i+1 < len(body) && body[i+1] == '^' {
+				out.WriteByte('^')
+				i++
+			}
+			classStart = i + 1
+		case c == '(':
+			n := t.translateGroup(&out, body[i:])
+			i += n - 1
+		case c == '+' && i > 0 && t.endsQuantifier(body[:i]):
+			t.fail(body[i-1:i+1], "possessive quantifier")
+			out.WriteByte(c)
+		case c == '{' && t.dialect == DialectPython && strings.HasPrefix(body[i:], "{,"):
+			out.WriteString("{0,")
+			i++
+		case c == '$':
+			if !t.multiline && t.dialect != DialectJavaScript {
+				t.note(fmt.Sprintf("$ also matches before a trailing newline in %s; in RE2 it only matches at the very end", t.dialect))
+			}
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// endsQuantifier reports whether the text before a '+' ends with a quantifier, making the '+' possessive.
+func (t *translator) endsQuantifier(before string) bool {
+	if t.dialect == DialectJavaScript || t.dialect == DialectPython {
+		return false
+	}
+	last := before[len(before)-1]
+	if strings.IndexByte("*+?", last) >= 0 {
+		// `\*+` is an escaped star followed by a normal plus.
+		backslashes := 0
+		for j := len(before) - 2; j >= 0 && before[j] == '\\'; j-- {
+			backslashes++
+		}
+		return backslashes%2 == 0
+	}
+	if last == '}' {
+		open := strings.LastIndexByte(before, '{')
+		return open >= 0 && quantifierEndRe.MatchString(before[open:])
+	}
+	return false
+}
+
+// translateEscape writes the translation of the escape sequence at the start of s and returns its length.
+func (t *translator) translateEscape(out *strings.Builder, s string, inClass bool) int {
+	c := s[1]
+	switch {
+	case c >= '1' && c <= '9':
+		end := 2
+		for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+			end++
+		}
+		t.fail(s[:end], "backreference")
+		out.WriteString(s[:end])
+		return end
+	case c == '0' && (len(s) == 2 || s[2] < '0' || s[2] > '7'):
+		out.WriteString(`\x00`)
+		return 2
+	case c == 'k' && len(s) > 2 && strings.IndexByte("<{'", s[2]) >= 0:
+		end := strings.IndexAny(s[3:], ">}'")
+		if end < 0 {
+			end = len(s) - 3
+		}
+		t.fail(s[:end+4], "named backreference")
+		out.WriteString(s[:end+4])
+		return end + 4
+	case c == 'g' && t.dialect == DialectPCRE:
+		m := pcreBackrefRe.FindString(s)
+		if m == "" {
+			m = s[:2]
+		}
+		t.fail(m, "backreference")
+		out.WriteString(m)
+		return len(m)
+	case c == 'u' || c == 'U' && t.dialect == DialectPython:
+		digits := 4
+		if c == 'U' {
+			digits = 8
+		}
+		if c == 'u' && len(s) > 2 && s[2] == '{' {
+			if end := strings.IndexByte(s, '}'); end > 0 {
+				out.WriteString(`\x{` + s[3:end] + `}`)
+				return end + 1
+			}
+		}
+		if len(s) >= 2+digits {
+			if v, err := strconv.ParseUint(s[2:2+digits], 16, 32); err == nil {
+				fmt.Fprintf(out, `\x{%X}`, v)
+				return 2 + digits
+			}
+		}
+	case c == 'c' && len(s) > 2 && ('A' <= s[2] && s[2] <= 'Z' || 'a' <= s[2] && s[2] <= 'z'):
+		fmt.Fprintf(out, `\x%02X`, s[2]&0x1f)
+		return 3
+	case c == 'e':
+		out.WriteString(`\x1B`)
+		return 2
+	case c == '/':
+		out.WriteByte('/')
+		return 2
+	case c == 'Z':
+		if t.dialect != DialectPython {
+			t.note(`\Z translated as \z, which does not match before a trailing newline`)
+		}
+		out.WriteString(`\z`)
+		return 2
+	case c == 'h' && t.dialect != DialectJavaScript:
+		if inClass {
+			out.WriteString(`\t\p{Zs}`)
+		} else {
+			out.WriteString(`[\t\p{Zs}]`)
+		}
+		return 2
+	case c == 'H' && t.dialect != DialectJavaScript && !inClass:
+		out.WriteString(`[^\t\p{Zs}]`)
+		return 2
+	case c == 'R' && t.dialect != DialectJavaScript && !inClass:
+		out.WriteString(`(?:\r\n|[\n\v\f\r\x{85}\x{2028}\x{2029}])`)
+		return 2
+	case strings.IndexByte("GKXHRC", c) >= 0:
+		t.fail(s[:2], "escape")
+		out.WriteString(s[:2])
+		return 2
+	case (c == 'p' || c == 'P') && len(s) > 2 && s[2] == '{':
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			break
+		}
+		name := s[3:end]
+		if posix, ok := javaPosixClasses[strings.TrimPrefix(name, "Is")]; ok && t.dialect == DialectJava {
+			class := "[:" + posix + ":]"
+			if c == 'P' {
+				class = "[:^" + posix + ":]"
+			}
+			if !inClass {
+				class = "[" + class + "]"
+			}
+			out.WriteString(class)
+			return end + 1
+		}
+		switch {
+		case unicodePropertyRe.MatchString(name):
+			name = name[strings.IndexByte(name, '=')+1:]
+		case strings.HasPrefix(name, "Is") && t.dialect == DialectJava:
+			name = strings.TrimPrefix(name, "Is")
+		case strings.HasPrefix(name, "In") && t.dialect == DialectJava:
+			t.fail(s[:end+1], "Unicode block")
+		}
+		out.WriteString(`\` + string(c) + "{" + name + "}")
+		return end + 1
+	case (c == 'w' || c == 'd' || c == 's' || c == 'b') && t.dialect == DialectPython:
+		t.note(`\w, \d, \s and \b are Unicode-aware in Python but ASCII-only in RE2`)
+	}
+	out.WriteString(s[:2])
+	return 2
+}
+
+// translateGroup writes the translation of the group opening at the start of s and returns its length.
+func (t *translator) translateGroup(out *strings.Builder, s string) int {
+	if !strings.HasPrefix(s, "(?") {
+		out.WriteByte('(')
+		return 1
+	}
+
+	if special := groupSpecialRe.FindString(s); special != "" {
+		switch special {
+		case "(?#":
+			end := strings.IndexByte(s, ')')
+			if end < 0 {
+				end = len(s) - 1
+			}
+			t.note("(?#...) comments were removed")
+			return end + 1
+		case "(?=", "(?!":
+			t.fail(special, "lookahead")
+		case "(?<=", "(?<!":
+			t.fail(special, "lookbehind")
+		case "(?>":
+			t.fail(special, "atomic group")
+		case "(?|":
+			t.fail(special, "branch reset group")
+		case "(?(":
+			t.fail(special, "conditional")
+		case "(?P=":
+			end := strings.IndexByte(s, ')')
+			if end > 0 {
+				special = s[:end+1]
+			}
+			t.fail(special, "named backreference")
+		default:
+			t.fail(special, "recursion or subroutine call")
+		}
+		out.WriteString(special)
+		return len(special)
+	}
+
+	for _, prefix := range []string{"(?P<", "(?<", "(?'"} {
+		if !strings.HasPrefix(s, prefix) {
+			continue
+		}
+		closing := ">"
+		if prefix == "(?'" {
+			closing = "'"
+		}
+		end := strings.Index(s[len(prefix):], closing)
+		if end < 0 {
+			break
+		}
+		name := s[len(prefix) : len(prefix)+end]
+		out.WriteString("(?P<" + name + ">")
+		return len(prefix) + end + 1
+	}
+
+	// Inline flags: keep the ones RE2 knows.
+	end := strings.IndexAny(s, ":)")
+	if end < 0 {
+		out.WriteString(s[:2])
+		return 2
+	}
+	var kept strings.Builder
+	for _, f := range s[2:end] {
+		switch {
+		case strings.ContainsRune("imsU-", f):
+			kept.WriteRune(f)
+		case f == 'x':
+			t.fail("(?x)", "verbose flag inside the pattern")
+		default:
+			t.note(fmt.Sprintf("inline flag %c has no RE2 equivalent and was ignored", f))
+		}
+	}
+	flags := strings.TrimSuffix(kept.String(), "-")
+	switch {
+	case flags == "" && s[end] == ')':
+		// Nothing left to set.
+	case flags == "":
+		out.WriteString("(?:")
+	default:
+		out.WriteString("(?" + flags + string(s[end]))
+	}
+	return end + 1
+}
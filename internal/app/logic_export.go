@@ -16,6 +16,29 @@ type exportJson struct {
 
 // GenerateExportJSONAll generates a JSON byte slice containing the regex and all matches.
 func GenerateExportJSONAll(regexStr string, matches [][]string) ([]byte, error) {
+	data := exportJson{
+		Regex:   regexStr,
+		Matches: allGroupMaps(matches),
+	}
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// GenerateExportJSONGroups generates a JSON byte slice containing the regex and specific capture groups.
+func GenerateExportJSONGroups(regexStr string, matches [][]string, groupInput string) ([]byte, error) {
+	groups, err := parseGroupNumbers(groupInput)
+	if err != nil {
+		return nil, err
+	}
+
+	data := exportJson{
+		Regex:   regexStr,
+		Matches: selectedGroupMaps(matches, groups),
+	}
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// allGroupMaps keys every group of each match by its number.
+func allGroupMaps(matches [][]string) []map[string]string {
 	var resultMatches []map[string]string
 	for _, match := range matches {
 		matchMap := make(map[string]string)
@@ -24,16 +47,29 @@ func GenerateExportJSONAll(regexStr string, matches [][]string) ([]byte, error)
 		}
 		resultMatches = append(resultMatches, matchMap)
 	}
+	return resultMatches
+}
 
-	data := exportJson{
-		Regex:   regexStr,
-		Matches: resultMatches,
+// selectedGroupMaps keys the given groups of each match by their number. Matches without
+// any of the groups are left out.
+func selectedGroupMaps(matches [][]string, groups []int) []map[string]string {
+	var processedMatches []map[string]string
+	for _, match := range matches {
+		processedMatch := make(map[string]string)
+		for _, g := range groups {
+			if g >= 0 && g < len(match) {
+				processedMatch[strconv.Itoa(g)] = match[g]
+			}
+		}
+		if len(processedMatch) > 0 {
+			processedMatches = append(processedMatches, processedMatch)
+		}
 	}
-	return json.MarshalIndent(data, "", "  ")
+	return processedMatches
 }
 
-// GenerateExportJSONGroups generates a JSON byte slice containing the regex and specific capture groups.
-func GenerateExportJSONGroups(regexStr string, matches [][]string, groupInput string) ([]byte, error) {
+// parseGroupNumbers parses a comma-separated list of group numbers.
+func parseGroupNumbers(groupInput string) ([]int, error) {
 	if groupInput == "" {
 		return nil, fmt.Errorf("group numbers cannot be empty")
 	}
@@ -50,25 +86,7 @@ func GenerateExportJSONGroups(regexStr string, matches [][]string, groupInput st
 		}
 		groups = append(groups, g)
 	}
-
-	var processedMatches []map[string]string
-	for _, match := range matches {
-		processedMatch := make(map[string]string)
-		for _, g := range groups {
-			if g >= 0 && g < len(match) {
-				processedMatch[strconv.Itoa(g)] = match[g]
-			}
-		}
-		if len(processedMatch) > 0 {
-			processedMatches = append(processedMatches, processedMatch)
-		}
-	}
-
-	data := exportJson{
-		Regex:   regexStr,
-		Matches: processedMatches,
-	}
-	return json.MarshalIndent(data, "", "  ")
+	return groups, nil
 }
 
 // GenerateExportCustom generates a formatted string based on a custom format string (e.g., "$1 - $2").
@@ -115,3 +133,76 @@ func GenerateExportCustom(matches [][]string, format string) ([]byte, error) {
 	}
 	return result.Bytes(), nil
 }
+
+// RecordMatches are the matches found in one record of the text.
+type RecordMatches struct {
+	Record  int // 1-based record number.
+	Matches [][]string
+}
+
+// GroupByRecord groups the matches by record, given the index of the record of each
+// match. Records without matches are left out.
+func GroupByRecord(matches [][]string, recordOf []int) []RecordMatches {
+	var records []RecordMatches
+	for i, match := range matches {
+		if len(records) == 0 || records[len(records)-1].Record != recordOf[i]+1 {
+			records = append(records, RecordMatches{Record: recordOf[i] + 1})
+		}
+		last := &records[len(records)-1]
+		last.Matches = append(last.Matches, match)
+	}
+	return records
+}
+
+type exportRecord struct {
+	Record  int                 `json:"record"`
+	Matches []map[string]string `json:"matches"`
+}
+
+type exportRecordsJson struct {
+	Regex   string         `json:"regex"`
+	Records []exportRecord `json:"records"`
+}
+
+// GenerateRecordExportJSONAll is GenerateExportJSONAll with the matches grouped by record.
+func GenerateRecordExportJSONAll(regexStr string, records []RecordMatches) ([]byte, error) {
+	data := exportRecordsJson{Regex: regexStr}
+	for _, r := range records {
+		data.Records = append(data.Records, exportRecord{r.Record, allGroupMaps(r.Matches)})
+	}
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// GenerateRecordExportJSONGroups is GenerateExportJSONGroups with the matches grouped by record.
+func GenerateRecordExportJSONGroups(regexStr string, records []RecordMatches, groupInput string) ([]byte, error) {
+	groups, err := parseGroupNumbers(groupInput)
+	if err != nil {
+		return nil, err
+	}
+	data := exportRecordsJson{Regex: regexStr}
+	for _, r := range records {
+		if maps := selectedGroupMaps(r.Matches, groups); len(maps) > 0 {
+			data.Records = append(data.Records, exportRecord{r.Record, maps})
+		}
+	}
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// GenerateRecordExportCustom is GenerateExportCustom with a blank line between the records.
+func GenerateRecordExportCustom(records []RecordMatches, format string) ([]byte, error) {
+	var result bytes.Buffer
+	for i, r := range records {
+		data, err := GenerateExportCustom(r.Matches, format)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			result.WriteString("\n\n")
+		}
+		result.Write(data)
+	}
+	if len(records) == 0 {
+		return GenerateExportCustom(nil, format)
+	}
+	return result.Bytes(), nil
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Filter is a boolean expression over patterns, such as `/ERROR/ AND NOT /healthcheck/ OR /panic/`.
+// NOT binds tighter than AND, which binds tighter than OR; parentheses group. A pattern
+// is written between slashes, with `\/` for a slash, and may be followed by the flags i, m, s and U.
+type Filter struct {
+	op       string // "pattern", "not", "and" or "or"
+	re       *regexp.Regexp
+	operands []*Filter
+}
+
+// Match reports whether the text satisfies the filter.
+func (f *Filter) Match(text string) bool {
+	switch f.op {
+	case "pattern":
+		return f.re.MatchString(text)
+	case "not":
+		return !f.operands[0].Match(text)
+	case "and":
+		for _, operand := range f.operands {
+			if !operand.Match(text) {
+				return false
+			}
+		}
+		return true
+	default: // "or"
+		for _, operand := range f.operands {
+			if operand.Match(text) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ParseFilter parses a filter expression. An empty expression returns a nil filter, which
+// selects everything.
+func ParseFilter(expr string) (*Filter, error) {
+	p := &filterParser{input: expr}
+	p.skipSpace()
+	if p.pos == len(p.input) {
+		return nil, nil
+	}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+	return f, nil
+}
+
+// ApplyFilter returns the indices of the units of the text, records or lines, that satisfy the filter.
+func ApplyFilter(text string, units []Span, f *Filter) []int {
+	var selected []int
+	for i, unit := range units {
+		if f.Match(text[unit.Start:unit.End]) {
+			selected = append(selected, i)
+		}
+	}
+	return selected
+}
+
+// FilterUnits returns the units a filter applies to: the records, or the lines if the text
+// is not split into records.
+func FilterUnits(text string, opts RecordOptions) ([]Span, error) {
+	if opts.Mode == RecordsNone {
+		opts.Mode = RecordsLines
+	}
+	return SplitRecords(text, opts)
+}
+
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func (p *filterParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("filter at %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// keyword consumes the case-insensitive keyword if it comes next.
+func (p *filterParser) keyword(word string) bool {
+	p.skipSpace()
+	end := p.pos + len(word)
+	if end > len(p.input) || !strings.EqualFold(p.input[p.pos:end], word) {
+		return false
+	}
+	if end < len(p.input) && (unicode.IsLetter(rune(p.input[end])) || unicode.IsDigit(rune(p.input[end]))) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *filterParser) parseOr() (*Filter, error) {
+	return p.parseBinary("or", p.parseAnd)
+}
+
+func (p *filterParser) parseAnd() (*Filter, error) {
+	return p.parseBinary("and", p.parseNot)
+}
+
+// parseBinary parses operands joined by the operator keyword.
+func (p *filterParser) parseBinary(op string, operand func() (*Filter, error)) (*Filter, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*Filter{first}
+	for p.keyword(op) {
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &Filter{op: op, operands: operands}, nil
+}
+
+func (p *filterParser) parseNot() (*Filter, error) {
+	if p.keyword("not") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &Filter{op: "not", operands: []*Filter{operand}}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (*Filter, error) {
+	p.skipSpace()
+	if p.pos == len(p.input) {
+		return nil, p.errorf("expected a /pattern/ or (")
+	}
+	switch p.input[p.pos] {
+	case '(':
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); p.pos == len(p.input) || p.input[p.pos] != ')' {
+			return nil, p.errorf("expected )")
+		}
+		p.pos++
+		return f, nil
+	case '/':
+		return p.parsePattern()
+	}
+	return nil, p.errorf("expected a /pattern/ or (, got %q", p.input[p.pos:])
+}
+
+// parsePattern parses /pattern/flags.
+func (p *filterParser) parsePattern() (*Filter, error) {
+	start := p.pos
+	p.pos++
+	var pattern strings.Builder
+	for {
+		if p.pos == len(p.input) {
+			p.pos = start
+			return nil, p.errorf("unterminated pattern")
+		}
+		c := p.input[p.pos]
+		if c == '/' {
+			break
+		}
+		if c == '\\' && p.pos+1 < len(p.input) && p.input[p.pos+1] == '/' {
+			c = '/'
+			p.pos++
+		}
+		pattern.WriteByte(c)
+		p.pos++
+	}
+	p.pos++
+
+	flagStart := p.pos
+	for p.pos < len(p.input) && strings.IndexByte("imsU", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+	source := pattern.String()
+	if flags := p.input[flagStart:p.pos]; flags != "" {
+		source = "(?" + flags + ")" + source
+	}
+	re, err := regexp.Compile(source)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("%v", err)
+	}
+	return &Filter{op: "pattern", re: re}, nil
+}
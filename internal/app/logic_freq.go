@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FreqEntry is a distinct value of a group and the number of matches that captured it.
+type FreqEntry struct {
+	Value string
+	Count int
+}
+
+// Frequencies counts the distinct values of a group, 0 for the whole match, like
+// sort | uniq -c. Matches where the group didn't participate count as an empty value.
+// The entries are sorted by decreasing count, or by value.
+func Frequencies(matches [][]string, group int, byValue bool) []FreqEntry {
+	counts := make(map[string]int)
+	for _, match := range matches {
+		if group < len(match) {
+			counts[match[group]]++
+		}
+	}
+	entries := make([]FreqEntry, 0, len(counts))
+	for value, count := range counts {
+		entries = append(entries, FreqEntry{Value: value, Count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !byValue && entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Value < entries[j].Value
+	})
+	return entries
+}
+
+// freqBarWidth is the width of the bar of the most frequent value.
+const freqBarWidth = 20
+
+// RenderFrequencies formats the entries like uniq -c, with the percentage of the matches
+// and a bar proportional to the count.
+func RenderFrequencies(entries []FreqEntry) string {
+	total, most := 0, 0
+	for _, entry := range entries {
+		total += entry.Count
+		most = max(most, entry.Count)
+	}
+	if total == 0 {
+		return ""
+	}
+	width := len(strconv.Itoa(most))
+
+	var builder strings.Builder
+	for _, entry := range entries {
+		length := max(1, entry.Count*freqBarWidth/most)
+		bar := strings.Repeat("█", length) + strings.Repeat(" ", freqBarWidth-length)
+		value := strconv.Quote(entry.Value)
+		builder.WriteString(fmt.Sprintf("%*d %5.1f%% %s %s\n", width, entry.Count, 100*float64(entry.Count)/float64(total),
+			bar, value[1:len(value)-1]))
+	}
+	return builder.String()
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GrepOptions selects the lines of the line view, like grep's -B, -A and -v.
+type GrepOptions struct {
+	Before int
+	After  int
+	Invert bool
+}
+
+// GrepLine is a line of the text shown in the line view.
+type GrepLine struct {
+	Number   int  // 1-based line number.
+	Start    int  // Offset of the line in the text.
+	End      int  // Offset of the end of the line, without the newline.
+	Selected bool // Whether the line was selected rather than shown as context.
+}
+
+// textLines returns the [start, end) offsets of the lines of the text. A final newline
+// doesn't start a new line.
+func textLines(text string) [][2]int {
+	var lines [][2]int
+	start := 0
+	for start < len(text) {
+		end := strings.IndexByte(text[start:], '\n')
+		if end < 0 {
+			lines = append(lines, [2]int{start, len(text)})
+			break
+		}
+		lines = append(lines, [2]int{start, start + end})
+		start += end + 1
+	}
+	return lines
+}
+
+// GrepLines returns the lines that contain a match, or don't with Invert, surrounded by
+// their context lines. A match spanning several lines selects all of them.
+func GrepLines(text string, matches [][]int, opts GrepOptions) []GrepLine {
+	lines := textLines(text)
+	lineOf := func(offset int) int {
+		return sort.Search(len(lines), func(i int) bool { return lines[i][1] >= offset })
+	}
+
+	matched := make([]bool, len(lines))
+	for _, m := range matches {
+		last := m[1]
+		if last > m[0] {
+			last-- // The line of the last character of the match.
+		}
+		for i := lineOf(m[0]); i < len(lines) && i <= lineOf(last); i++ {
+			matched[i] = true
+		}
+	}
+
+	shown := make([]bool, len(lines))
+	selected := make([]bool, len(lines))
+	for i := range lines {
+		if matched[i] == opts.Invert {
+			continue
+		}
+		selected[i] = true
+		for j := max(0, i-opts.Before); j <= min(len(lines)-1, i+opts.After); j++ {
+			shown[j] = true
+		}
+	}
+
+	var result []GrepLine
+	for i, line := range lines {
+		if shown[i] {
+			result = append(result, GrepLine{Number: i + 1, Start: line[0], End: line[1], Selected: selected[i]})
+		}
+	}
+	return result
+}
+
+// UnitLines returns the lines of the units, e.g. the records selected by a filter, as
+// selected lines. The units must be in order.
+func UnitLines(text string, units []Span) []GrepLine {
+	var result []GrepLine
+	lines := textLines(text)
+	i := 0
+	for _, unit := range units {
+		for i < len(lines) && lines[i][1] < unit.Start {
+			i++
+		}
+		for j := i; j < len(lines) && lines[j][0] <= unit.End; j++ {
+			if len(result) == 0 || result[len(result)-1].Number < j+1 {
+				result = append(result, GrepLine{Number: j + 1, Start: lines[j][0], End: lines[j][1], Selected: true})
+			}
+			if lines[j][1] >= unit.End {
+				break
+			}
+		}
+	}
+	return result
+}
+
+// grepPrefix returns the line number followed by ':' for a selected line or '-' for a context line.
+func grepPrefix(line GrepLine, width int) string {
+	separator := "-"
+	if line.Selected {
+		separator = ":"
+	}
+	return fmt.Sprintf("%*d%s", width, line.Number, separator)
+}
+
+// RenderGrepLines formats the lines like grep -n: "--" separates the groups of lines that
+// are not adjacent.
+func RenderGrepLines(text string, lines []GrepLine) string {
+	var builder strings.Builder
+	for i, line := range lines {
+		if i > 0 && line.Number != lines[i-1].Number+1 {
+			builder.WriteString("--\n")
+		}
+		builder.WriteString(grepPrefix(line, 0))
+		builder.WriteString(text[line.Start:line.End])
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Headless output formats
+const (
+	FormatLines    = "lines"    // The records or lines that pass the filter and contain a match.
+	FormatJSON     = "json"     // Like OptJsonAll.
+	FormatGroups   = "groups"   // Like OptJsonGroups.
+	FormatCustom   = "custom"   // Like OptCustom.
+	FormatCounts   = "counts"   // Like OptFrequencies.
+	FormatStats    = "stats"    // Like OptStats.
+	FormatCSV      = "csv"      // Like OptCsv.
+	FormatTSV      = "tsv"      // Like OptTsv.
+	FormatNDJSON   = "ndjson"   // Like OptNdjson, but searching line by line, see NDJSONWriter.Stream.
+	FormatTemplate = "template" // Like OptTemplate.
+)
+
+// HeadlessOptions configures a search without the TUI.
+type HeadlessOptions struct {
+	Pattern  string
+	Filter   string
+	Records  RecordOptions
+	Format   string
+	Groups   string     // Group numbers for FormatGroups, or the columns of FormatCSV and FormatTSV.
+	Header   HeaderMode // Header row of FormatCSV and FormatTSV.
+	Custom   string     // Format string for FormatCustom.
+	Template string     // Go template for FormatTemplate.
+	Stages   []Stage    // Pipeline stages, whose matches are nested in the JSON formats.
+
+	CountGroup   string // Group number or name counted by FormatCounts, empty for the whole match.
+	CountByValue bool   // Whether FormatCounts sorts by value rather than by count.
+	StatGroup    string // Group number or name summarised by FormatStats, empty for the whole match.
+}
+
+// NewHeadlessNDJSON returns the writer of FormatNDJSON to w. It streams the input line by
+// line rather than searching it as a whole, so it supports the pattern and the filter but
+// neither records nor stages.
+func NewHeadlessNDJSON(w io.Writer, opts HeadlessOptions) (*NDJSONWriter, error) {
+	if opts.Pattern == "" {
+		return nil, fmt.Errorf("the %s format needs a pattern", FormatNDJSON)
+	}
+	if opts.Records.Mode != RecordsNone || len(opts.Stages) > 0 {
+		return nil, fmt.Errorf("the %s format searches line by line, without records or stages", FormatNDJSON)
+	}
+	re, err := regexp.Compile(opts.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := ParseFilter(opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return NewNDJSONWriter(w, re, filter), nil
+}
+
+// RunHeadless searches the text like the TUI does and returns the output in the chosen format.
+func RunHeadless(text string, opts HeadlessOptions) ([]byte, error) {
+	if opts.Format == FormatNDJSON {
+		var buffer bytes.Buffer
+		writer, err := NewHeadlessNDJSON(&buffer, opts)
+		if err != nil {
+			return nil, err
+		}
+		err = writer.Stream(strings.NewReader(text))
+		return buffer.Bytes(), err
+	}
+	filter, err := ParseFilter(opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var units []Span
+	if opts.Records.Mode != RecordsNone || filter != nil || opts.Format == FormatLines {
+		if units, err = FilterUnits(text, opts.Records); err != nil {
+			return nil, err
+		}
+	} else {
+		units = []Span{{0, len(text)}}
+	}
+	selected := make([]int, len(units))
+	for i := range units {
+		selected[i] = i
+	}
+	if filter != nil {
+		selected = ApplyFilter(text, units, filter)
+	}
+	searchUnits := make([]Span, len(selected))
+	for i, unit := range selected {
+		searchUnits[i] = units[unit]
+	}
+
+	if opts.Format == FormatLines {
+		var builder strings.Builder
+		for _, unit := range searchUnits {
+			if opts.Pattern != "" {
+				if _, matches, _, err := SearchRecords(opts.Pattern, text, []Span{unit}); err != nil {
+					return nil, err
+				} else if len(matches) == 0 {
+					continue
+				}
+			}
+			builder.WriteString(strings.TrimSuffix(text[unit.Start:unit.End], "\n"))
+			builder.WriteString("\n")
+		}
+		return []byte(builder.String()), nil
+	}
+
+	if opts.Pattern == "" {
+		return nil, fmt.Errorf("a pattern is needed for the %s format", opts.Format)
+	}
+	indices, matches, recordOf, err := SearchRecords(opts.Pattern, text, searchUnits)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Records.Mode != RecordsNone {
+		for i, r := range recordOf {
+			recordOf[i] = selected[r]
+		}
+	} else {
+		recordOf = nil
+	}
+	if opts.Format == FormatCounts || opts.Format == FormatStats {
+		re, err := regexp.Compile(opts.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Format == FormatStats {
+			group, err := groupIndex(re, opts.StatGroup)
+			if err != nil {
+				return nil, err
+			}
+			return []byte(RenderStats(NumericStats(matches, group))), nil
+		}
+		group, err := groupIndex(re, opts.CountGroup)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(RenderFrequencies(Frequencies(matches, group, opts.CountByValue))), nil
+	}
+	if opts.Format == FormatCSV || opts.Format == FormatTSV {
+		comma := ','
+		if opts.Format == FormatTSV {
+			comma = '\t'
+		}
+		return GenerateExportCSV(opts.Pattern, matches, opts.Groups, opts.Header, comma)
+	}
+	if opts.Format == FormatTemplate {
+		return GenerateExportTemplate(opts.Pattern, text, indices, matches, opts.Template)
+	}
+	if len(opts.Stages) > 0 && (opts.Format == FormatJSON || opts.Format == FormatGroups) {
+		pipeline, err := RunPipeline(opts.Pattern, matches, opts.Stages)
+		if err != nil {
+			return nil, err
+		}
+		groups := ""
+		if opts.Format == FormatGroups {
+			groups = opts.Groups
+		}
+		return GeneratePipelineExportJSON(opts.Pattern, opts.Stages, pipeline, recordOf, groups)
+	}
+	if opts.Records.Mode != RecordsNone {
+		records := GroupByRecord(matches, recordOf)
+		switch opts.Format {
+		case FormatJSON:
+			return GenerateRecordExportJSONAll(opts.Pattern, records)
+		case FormatGroups:
+			return GenerateRecordExportJSONGroups(opts.Pattern, records, opts.Groups)
+		case FormatCustom:
+			return GenerateRecordExportCustom(records, opts.Custom)
+		}
+	} else {
+		switch opts.Format {
+		case FormatJSON:
+			return GenerateExportJSONAll(opts.Pattern, matches)
+		case FormatGroups:
+			return GenerateExportJSONGroups(opts.Pattern, matches, opts.Groups)
+		case FormatCustom:
+			return GenerateExportCustom(matches, opts.Custom)
+		}
+	}
+	return nil, fmt.Errorf("unknown format %q", opts.Format)
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectLibraryNames are the names of the project-level library file, looked up in the
+// working directory and its parents.
+var ProjectLibraryNames = []string{".regex-find-library.yaml", ".regex-find-library.yml", ".regex-find-library.json"}
+
+// LibraryFile is the content of a user-defined pattern library.
+type LibraryFile struct {
+	Name     string     `json:"name" yaml:"name"` // Section name in the library; defaults to the file name.
+	Patterns []HelpItem `json:"patterns" yaml:"patterns"`
+}
+
+// LibraryPaths returns the library files to load: every JSON or YAML file in the
+// regex-find/library directory of the user config directory, followed by the nearest
+// project-level library file.
+func LibraryPaths() []string {
+	var paths []string
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dir := filepath.Join(configDir, "regex-find", "library")
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, entry := range entries {
+				if !entry.IsDir() && isLibraryFile(entry.Name()) {
+					paths = append(paths, filepath.Join(dir, entry.Name()))
+				}
+			}
+		}
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return paths
+	}
+	for {
+		for _, name := range ProjectLibraryNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return append(paths, path)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return paths
+		}
+		dir = parent
+	}
+}
+
+func isLibraryFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// LoadLibraries loads the library files into one section each. Files that cannot be loaded
+// are skipped and reported in the returned error.
+func LoadLibraries(paths []string) ([]HelpSection, error) {
+	var sections []HelpSection
+	var errs []error
+	for _, path := range paths {
+		section, err := LoadLibraryFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sections = append(sections, section)
+	}
+	return sections, errors.Join(errs...)
+}
+
+// LoadLibraryFile reads a library file, as YAML or JSON depending on its extension.
+func LoadLibraryFile(path string) (HelpSection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return HelpSection{}, err
+	}
+	var file LibraryFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return HelpSection{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i, item := range file.Patterns {
+		if item.Title == "" || item.Pattern == "" {
+			return HelpSection{}, fmt.Errorf("%s: pattern %d needs a title and a pattern", path, i+1)
+		}
+		if strings.Trim(item.Flags, "imsU") != "" {
+			return HelpSection{}, fmt.Errorf("%s: %s: unknown flags %q, expected some of \"imsU\"", path, item.Title, item.Flags)
+		}
+	}
+
+	source := file.Name
+	if source == "" {
+		source = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return HelpSection{Source: source, Items: file.Patterns}, nil
+}
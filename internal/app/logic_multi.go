@@ -0,0 +1,51 @@
+package app
+
+import "sort"
+
+// Segment is a part of the text matched by the same set of patterns.
+type Segment struct {
+	Start    int
+	End      int
+	Patterns []int // Indices of the patterns that match the segment, in order.
+}
+
+// OverlapSegments cuts the matches of several patterns into segments so that the parts
+// matched by more than one pattern can be told apart. Empty matches are ignored.
+func OverlapSegments(matchSets [][][]int) []Segment {
+	type boundary struct {
+		offset  int
+		pattern int
+		delta   int
+	}
+	var boundaries []boundary
+	for p, matches := range matchSets {
+		for _, m := range matches {
+			if m[1] > m[0] {
+				boundaries = append(boundaries, boundary{m[0], p, 1}, boundary{m[1], p, -1})
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].offset < boundaries[j].offset })
+
+	var segments []Segment
+	active := make([]int, len(matchSets))
+	for i := 0; i < len(boundaries); {
+		offset := boundaries[i].offset
+		for ; i < len(boundaries) && boundaries[i].offset == offset; i++ {
+			active[boundaries[i].pattern] += boundaries[i].delta
+		}
+		if i == len(boundaries) {
+			break
+		}
+		var patterns []int
+		for p, count := range active {
+			if count > 0 {
+				patterns = append(patterns, p)
+			}
+		}
+		if len(patterns) > 0 {
+			segments = append(segments, Segment{Start: offset, End: boundaries[i].offset, Patterns: patterns})
+		}
+	}
+	return segments
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NDJSONMatch is a match as written by NDJSONWriter, one JSON object per line.
+type NDJSONMatch struct {
+	Index  int               `json:"index"`
+	File   string            `json:"file,omitempty"`
+	Start  int               `json:"start"` // Byte offset of the match.
+	End    int               `json:"end"`   // Byte offset after the match.
+	Line   int               `json:"line"`  // 1-based line of the start of the match.
+	Col    int               `json:"col"`   // 1-based column of the start of the match, in characters.
+	Groups map[string]string `json:"groups"`
+}
+
+// NDJSONWriter writes matches as NDJSON as soon as they are found, keying the groups by
+// number and, for named groups, by name.
+type NDJSONWriter struct {
+	File    string // Source file name written with each match, omitted if empty.
+	encoder *json.Encoder
+	re      *regexp.Regexp
+	filter  *Filter
+	index   int
+}
+
+// NewNDJSONWriter returns a writer of the matches of re, in the lines that pass the filter
+// if it isn't nil, to w.
+func NewNDJSONWriter(w io.Writer, re *regexp.Regexp, filter *Filter) *NDJSONWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &NDJSONWriter{encoder: encoder, re: re, filter: filter}
+}
+
+// write writes the next match. Indices continue across files.
+func (n *NDJSONWriter) write(start, end, line, col int, groups []string) error {
+	match := NDJSONMatch{Index: n.index, File: n.File, Start: start, End: end, Line: line, Col: col,
+		Groups: make(map[string]string, 2*len(groups))}
+	names := n.re.SubexpNames()
+	for g, group := range groups {
+		match.Groups[strconv.Itoa(g)] = group
+		if g < len(names) && names[g] != "" {
+			match.Groups[names[g]] = group
+		}
+	}
+	n.index++
+	return n.encoder.Encode(match)
+}
+
+// Stream searches r line by line, like grep, and writes each match of the lines that pass
+// the filter. Only a line is held in memory, so the pattern can't span lines.
+func (n *NDJSONWriter) Stream(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	offset := 0
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		text := strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if n.filter == nil || n.filter.Match(text) {
+			for _, loc := range n.re.FindAllStringSubmatchIndex(text, -1) {
+				groups := make([]string, len(loc)/2)
+				for g := range groups {
+					if loc[2*g] >= 0 {
+						groups[g] = text[loc[2*g]:loc[2*g+1]]
+					}
+				}
+				col := utf8.RuneCountInString(text[:loc[0]]) + 1
+				if err := n.write(offset+loc[0], offset+loc[1], lineNumber, col, groups); err != nil {
+					return err
+				}
+			}
+		}
+		offset += len(line)
+	}
+}
+
+// lineCounter finds the line and column of byte offsets of a text, scanning it once if the
+// offsets increase.
+type lineCounter struct {
+	text      string
+	line      int
+	lineStart int
+	pos       int
+}
+
+// position returns the 1-based line and column, in characters, of an offset.
+func (c *lineCounter) position(offset int) (int, int) {
+	if offset < c.pos || c.line == 0 {
+		c.line, c.lineStart, c.pos = 1, 0, 0
+	}
+	for ; c.pos < offset; c.pos++ {
+		if c.text[c.pos] == '\n' {
+			c.line, c.lineStart = c.line+1, c.pos+1
+		}
+	}
+	return c.line, utf8.RuneCountInString(c.text[c.lineStart:offset]) + 1
+}
+
+// GenerateExportNDJSON generates NDJSON of the matches of the text, given by their byte
+// offsets and groups, in the order of the text.
+func GenerateExportNDJSON(regexStr, text string, indices [][]int, matches [][]string) ([]byte, error) {
+	re, err := regexp.Compile(regexStr)
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	writer := NewNDJSONWriter(&buffer, re, nil)
+	counter := lineCounter{text: text}
+	for i, match := range matches {
+		line, col := counter.position(indices[i][0])
+		if err := writer.write(indices[i][0], indices[i][1], line, col, match); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
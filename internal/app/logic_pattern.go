@@ -0,0 +1,291 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenKind classifies a piece of regex source text.
+type tokenKind int
+
+const (
+	tokLiteral    tokenKind = iota // A single literal character, possibly escaped (e.g. `a`, `\.`).
+	tokEscape                      // A non-literal escape such as `\d`, `\b` or `\p{Greek}`.
+	tokClass                       // A bracketed character class.
+	tokDot                         // The `.` wildcard.
+	tokAnchor                      // `^` or `$`.
+	tokQuant                       // A repetition operator, including a trailing `?` for laziness.
+	tokFlags                       // A standalone flag setting such as `(?i)`.
+	tokGroupOpen                   // The opening of a group, e.g. `(`, `(?:` or `(?P<name>`.
+	tokGroupClose                  // `)`.
+	tokAlt                         // `|`.
+)
+
+// patternToken is a lexical element of a regex pattern.
+// Text always holds the exact source text so that a token list can be joined back losslessly.
+type patternToken struct {
+	Kind      tokenKind
+	Text      string
+	Capturing bool   // Only for tokGroupOpen.
+	Name      string // Only for named tokGroupOpen.
+}
+
+var repeatRe = regexp.MustCompile(`^\{\d+(,\d*)?\}\??`)
+
+// tokenizePattern splits a pattern into tokens without interpreting their meaning.
+// It understands escapes, character classes, `\Q...\E` quoting and the group syntaxes accepted by RE2.
+func tokenizePattern(pattern string) ([]patternToken, error) {
+	var tokens []patternToken
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		switch c {
+		case '\\':
+			if i+1 >= len(pattern) {
+				return nil, fmt.Errorf("trailing backslash at end of pattern")
+			}
+			if pattern[i+1] == 'Q' {
+				end := strings.Index(pattern[i+2:], `\E`)
+				quoted := pattern[i+2:]
+				next := len(pattern)
+				if end >= 0 {
+					quoted = pattern[i+2 : i+2+end]
+					next = i + 2 + end + 2
+				}
+				for _, r := range quoted {
+					tokens = append(tokens, patternToken{Kind: tokLiteral, Text: regexp.QuoteMeta(string(r))})
+				}
+				i = next
+				continue
+			}
+			text, kind := scanEscape(pattern[i:])
+			tokens = append(tokens, patternToken{Kind: kind, Text: text})
+			i += len(text)
+		case '[':
+			n, err := scanClass(pattern[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, patternToken{Kind: tokClass, Text: pattern[i : i+n]})
+			i += n
+		case '(':
+			tok, n, err := scanGroupOpen(pattern[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i += n
+		case ')':
+			tokens = append(tokens, patternToken{Kind: tokGroupClose, Text: ")"})
+			i++
+		case '|':
+			tokens = append(tokens, patternToken{Kind: tokAlt, Text: "|"})
+			i++
+		case '.':
+			tokens = append(tokens, patternToken{Kind: tokDot, Text: "."})
+			i++
+		case '^', '$':
+			tokens = append(tokens, patternToken{Kind: tokAnchor, Text: string(c)})
+			i++
+		case '*', '+', '?':
+			n := 1
+			if i+1 < len(pattern) && pattern[i+1] == '?' {
+				n = 2
+			}
+			tokens = append(tokens, patternToken{Kind: tokQuant, Text: pattern[i : i+n]})
+			i += n
+		case '{':
+			if m := repeatRe.FindString(pattern[i:]); m != "" {
+				tokens = append(tokens, patternToken{Kind: tokQuant, Text: m})
+				i += len(m)
+				continue
+			}
+			tokens = append(tokens, patternToken{Kind: tokLiteral, Text: "{"})
+			i++
+		default:
+			// Keep multi-byte characters together.
+			_, size := utf8.DecodeRuneInString(pattern[i:])
+			tokens = append(tokens, patternToken{Kind: tokLiteral, Text: pattern[i : i+size]})
+			i += size
+		}
+	}
+	return tokens, nil
+}
+
+// scanEscape returns the source text of the escape sequence at the start of s and its kind.
+func scanEscape(s string) (string, tokenKind) {
+	c := s[1]
+	switch c {
+	case 'd', 'D', 's', 'S', 'w', 'W', 'b', 'B', 'A', 'z':
+		return s[:2], tokEscape
+	case 'p', 'P':
+		if len(s) > 2 && s[2] == '{' {
+			if end := strings.IndexByte(s, '}'); end >= 0 {
+				return s[:end+1], tokEscape
+			}
+		}
+		if len(s) > 2 {
+			return s[:3], tokEscape
+		}
+		return s[:2], tokEscape
+	case 'x':
+		if len(s) > 2 && s[2] == '{' {
+			if end := strings.IndexByte(s, '}'); end >= 0 {
+				return s[:end+1], tokLiteral
+			}
+		}
+		if len(s) >= 4 {
+			return s[:4], tokLiteral
+		}
+		return s, tokLiteral
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		n := 2
+		for n < 4 && n < len(s) && s[n] >= '0' && s[n] <= '7' {
+			n++
+		}
+		return s[:n], tokLiteral
+	}
+	_, size := utf8.DecodeRuneInString(s[1:])
+	return s[:1+size], tokLiteral
+}
+
+// scanClass returns the length of the character class at the start of s.
+func scanClass(s string) (int, error) {
+	i := 1
+	if i < len(s) && s[i] == '^' {
+		i++
+	}
+	// A ']' right after the opening bracket is a literal.
+	if i < len(s) && s[i] == ']' {
+		i++
+	}
+	for i < len(s) {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			text, _ := scanEscape(s[i:])
+			i += len(text)
+		case s[i] == '[' && strings.HasPrefix(s[i:], "[:"):
+			end := strings.Index(s[i+2:], ":]")
+			if end < 0 {
+				i++
+				continue
+			}
+			i += 2 + end + 2
+		case s[i] == ']':
+			return i + 1, nil
+		default:
+			i++
+		}
+	}
+	return 0, fmt.Errorf("missing closing ]: %s", s)
+}
+
+// scanGroupOpen parses the group opening at the start of s.
+func scanGroupOpen(s string) (patternToken, int, error) {
+	if !strings.HasPrefix(s, "(?") {
+		return patternToken{Kind: tokGroupOpen, Text: "(", Capturing: true}, 1, nil
+	}
+	for _, prefix := range []string{"(?P<", "(?<"} {
+		if strings.HasPrefix(s, prefix) {
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return patternToken{}, 0, fmt.Errorf("invalid named capture: %s", s)
+			}
+			return patternToken{Kind: tokGroupOpen, Text: s[:end+1], Capturing: true, Name: s[len(prefix):end]}, end + 1, nil
+		}
+	}
+	for i := 2; i < len(s); i++ {
+		switch s[i] {
+		case ':':
+			return patternToken{Kind: tokGroupOpen, Text: s[:i+1]}, i + 1, nil
+		case ')':
+			return patternToken{Kind: tokFlags, Text: s[:i+1]}, i + 1, nil
+		}
+	}
+	return patternToken{}, 0, fmt.Errorf("invalid or unsupported group syntax: %s", s)
+}
+
+// patternNode is a node of the structural tree built from the tokens.
+// Leaf nodes carry a single token; group nodes carry their opening token and one sequence per alternative.
+type patternNode struct {
+	tok      patternToken
+	branches [][]*patternNode
+}
+
+func (n *patternNode) isGroup() bool {
+	return n.tok.Kind == tokGroupOpen
+}
+
+// parsePatternTree builds a tree from the pattern. The returned root is a pseudo-group without an opening token.
+func parsePatternTree(pattern string) (*patternNode, error) {
+	tokens, err := tokenizePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &patternNode{branches: [][]*patternNode{{}}}
+	stack := []*patternNode{root}
+	for _, tok := range tokens {
+		top := stack[len(stack)-1]
+		last := len(top.branches) - 1
+		switch tok.Kind {
+		case tokGroupOpen:
+			group := &patternNode{tok: tok, branches: [][]*patternNode{{}}}
+			top.branches[last] = append(top.branches[last], group)
+			stack = append(stack, group)
+		case tokGroupClose:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("unexpected )")
+			}
+			stack = stack[:len(stack)-1]
+		case tokAlt:
+			top.branches = append(top.branches, []*patternNode{})
+		default:
+			top.branches[last] = append(top.branches[last], &patternNode{tok: tok})
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("missing closing )")
+	}
+	return root, nil
+}
+
+// renderPattern joins a tree back into pattern source.
+func renderPattern(root *patternNode) string {
+	var builder strings.Builder
+	renderBranches(&builder, root.branches)
+	return builder.String()
+}
+
+func renderBranches(builder *strings.Builder, branches [][]*patternNode) {
+	for i, branch := range branches {
+		if i > 0 {
+			builder.WriteString("|")
+		}
+		for _, node := range branch {
+			renderNode(builder, node)
+		}
+	}
+}
+
+func renderNode(builder *strings.Builder, node *patternNode) {
+	builder.WriteString(node.tok.Text)
+	if node.isGroup() {
+		renderBranches(builder, node.branches)
+		builder.WriteString(")")
+	}
+}
+
+// walkGroups visits every group node in the order of its opening parenthesis,
+// which is also the order in which capture groups are numbered.
+func walkGroups(node *patternNode, visit func(*patternNode)) {
+	for _, branch := range node.branches {
+		for _, child := range branch {
+			if child.isGroup() {
+				visit(child)
+				walkGroups(child, visit)
+			}
+		}
+	}
+}
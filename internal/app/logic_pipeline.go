@@ -0,0 +1,132 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Stage is a step of an extraction pipeline: its pattern searches a group of every match
+// of the previous stage, the main pattern being the first stage.
+type Stage struct {
+	Group   string `json:"group"` // Group number or name, empty for the whole match.
+	Pattern string `json:"pattern"`
+}
+
+// PipelineMatch is a match with the matches of the next stage in its group.
+type PipelineMatch struct {
+	Groups   []string
+	Children []PipelineMatch
+}
+
+// ParseStage parses a stage written GROUP:PATTERN, such as 1:(\w+)=(\w+) or :\d+ for the
+// whole match.
+func ParseStage(s string) (Stage, error) {
+	group, pattern, found := strings.Cut(s, ":")
+	if !found || pattern == "" {
+		return Stage{}, fmt.Errorf("invalid stage %q, expected GROUP:PATTERN", s)
+	}
+	return Stage{Group: group, Pattern: pattern}, nil
+}
+
+// groupIndex resolves the group of a stage in the pattern of the previous stage.
+func groupIndex(re *regexp.Regexp, group string) (int, error) {
+	if group == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(group); err == nil {
+		if n < 0 || n > re.NumSubexp() {
+			return 0, fmt.Errorf("%s has no group %d", re, n)
+		}
+		return n, nil
+	}
+	if n := re.SubexpIndex(group); n >= 0 {
+		return n, nil
+	}
+	return 0, fmt.Errorf("%s has no group named %q", re, group)
+}
+
+// RunPipeline runs the stages over the matches of the main pattern.
+func RunPipeline(pattern string, matches [][]string, stages []Stage) ([]PipelineMatch, error) {
+	prev, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*regexp.Regexp, len(stages))
+	groups := make([]int, len(stages))
+	for i, stage := range stages {
+		if res[i], err = regexp.Compile(stage.Pattern); err != nil {
+			return nil, fmt.Errorf("stage %d: %w", i+2, err)
+		}
+		if groups[i], err = groupIndex(prev, stage.Group); err != nil {
+			return nil, fmt.Errorf("stage %d: %w", i+2, err)
+		}
+		prev = res[i]
+	}
+
+	var apply func(matches [][]string, level int) []PipelineMatch
+	apply = func(matches [][]string, level int) []PipelineMatch {
+		result := make([]PipelineMatch, len(matches))
+		for i, match := range matches {
+			result[i].Groups = match
+			if level < len(stages) {
+				result[i].Children = apply(res[level].FindAllStringSubmatch(match[groups[level]], -1), level+1)
+			}
+		}
+		return result
+	}
+	return apply(matches, 0), nil
+}
+
+type exportNode struct {
+	Record  int               `json:"record,omitempty"`
+	Groups  map[string]string `json:"groups"`
+	Matches []exportNode      `json:"matches,omitempty"`
+}
+
+type exportPipelineJson struct {
+	Regex   string       `json:"regex"`
+	Stages  []Stage      `json:"stages"`
+	Matches []exportNode `json:"matches"`
+}
+
+// GeneratePipelineExportJSON generates nested JSON: each match holds the matches of the
+// next stage. If groupInput is set, only these groups of the main pattern are exported.
+// If recordOf is set, each match of the main pattern has the number of its record.
+func GeneratePipelineExportJSON(regexStr string, stages []Stage, matches []PipelineMatch, recordOf []int, groupInput string) ([]byte, error) {
+	var groups []int
+	if groupInput != "" {
+		var err error
+		if groups, err = parseGroupNumbers(groupInput); err != nil {
+			return nil, err
+		}
+	}
+
+	var nodes func(matches []PipelineMatch) []exportNode
+	nodes = func(matches []PipelineMatch) []exportNode {
+		result := make([]exportNode, len(matches))
+		for i, m := range matches {
+			result[i] = exportNode{Groups: allGroupMaps([][]string{m.Groups})[0], Matches: nodes(m.Children)}
+		}
+		return result
+	}
+
+	data := exportPipelineJson{Regex: regexStr, Stages: stages}
+	for i, m := range matches {
+		node := exportNode{Groups: allGroupMaps([][]string{m.Groups})[0], Matches: nodes(m.Children)}
+		if groups != nil {
+			selected := selectedGroupMaps([][]string{m.Groups}, groups)
+			if len(selected) == 0 {
+				continue
+			}
+			node.Groups = selected[0]
+		}
+		if recordOf != nil {
+			node.Record = recordOf[i] + 1
+		}
+		data.Matches = append(data.Matches, node)
+	}
+	return json.MarshalIndent(data, "", "  ")
+}
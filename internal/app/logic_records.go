@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RecordMode selects how the text is split into records. The pattern is applied to each
+// record on its own, so that anchors are relative to the record.
+type RecordMode int
+
+// Record modes, in the order of the options form.
+const (
+	RecordsNone       RecordMode = iota // The whole text is a single record.
+	RecordsLines                        // One record per line.
+	RecordsParagraphs                   // Records separated by blank lines.
+	RecordsDelimiter                    // Records separated by the matches of a regex.
+	RecordsStart                        // Records starting at the lines whose beginning matches a regex.
+)
+
+// RecordModeNames are the names of the record modes on the command line, in the order of RecordMode.
+var RecordModeNames = []string{"whole", "lines", "paragraphs", "delimiter", "start"}
+
+// ParseRecordMode returns the record mode of a name of RecordModeNames.
+func ParseRecordMode(name string) (RecordMode, error) {
+	for i, n := range RecordModeNames {
+		if n == name {
+			return RecordMode(i), nil
+		}
+	}
+	return RecordsNone, fmt.Errorf("unknown record mode %q, expected one of %s", name, strings.Join(RecordModeNames, ", "))
+}
+
+// RecordOptions configures the splitting of the text into records.
+type RecordOptions struct {
+	Mode      RecordMode
+	Separator string // Regex of the delimiter or of the start of a record.
+}
+
+var blankLinesRe = regexp.MustCompile(`\n(?:[ \t]*\n)+`)
+
+// SplitRecords returns the spans of the records of the text.
+func SplitRecords(text string, opts RecordOptions) ([]Span, error) {
+	switch opts.Mode {
+	case RecordsLines:
+		var records []Span
+		for _, line := range textLines(text) {
+			records = append(records, Span{line[0], line[1]})
+		}
+		return records, nil
+	case RecordsParagraphs:
+		return splitByMatches(text, blankLinesRe, true), nil
+	case RecordsDelimiter, RecordsStart:
+		if opts.Separator == "" {
+			return nil, fmt.Errorf("the record separator regex cannot be empty")
+		}
+		re, err := regexp.Compile(opts.Separator)
+		if err != nil {
+			return nil, fmt.Errorf("invalid record separator: %w", err)
+		}
+		if opts.Mode == RecordsDelimiter {
+			return splitByMatches(text, re, false), nil
+		}
+		return splitAtStarts(text, re), nil
+	}
+	return []Span{{0, len(text)}}, nil
+}
+
+// splitByMatches returns the spans between the matches of re. A trailing empty record is
+// dropped, and so are all the blank ones if skipBlank is set.
+func splitByMatches(text string, re *regexp.Regexp, skipBlank bool) []Span {
+	var records []Span
+	start := 0
+	add := func(end int) {
+		if !skipBlank || strings.TrimSpace(text[start:end]) != "" {
+			records = append(records, Span{start, end})
+		}
+	}
+	for _, m := range re.FindAllStringIndex(text, -1) {
+		if m[1] == m[0] {
+			continue // An empty delimiter would split between every character.
+		}
+		add(m[0])
+		start = m[1]
+	}
+	if start < len(text) {
+		add(len(text))
+	}
+	return records
+}
+
+// splitAtStarts starts a record at every line that re matches at its beginning. The lines
+// before the first such line form a record of their own.
+func splitAtStarts(text string, re *regexp.Regexp) []Span {
+	var records []Span
+	start := 0
+	for _, line := range textLines(text) {
+		loc := re.FindStringIndex(text[line[0]:line[1]])
+		if loc == nil || loc[0] != 0 || line[0] == 0 {
+			continue
+		}
+		records = append(records, Span{start, line[0] - 1}) // Without the newline.
+		start = line[0]
+	}
+	if start < len(text) {
+		records = append(records, Span{start, len(strings.TrimSuffix(text, "\n"))})
+	}
+	return records
+}
+
+// SearchRecords applies the pattern to each record. It returns the match indices in the
+// whole text, the submatches, and the index of the record of each match.
+func SearchRecords(regexStr, text string, records []Span) ([][]int, [][]string, []int, error) {
+	if regexStr == "" {
+		return nil, nil, nil, nil
+	}
+	re, err := regexp.Compile(regexStr)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var indices [][]int
+	var matches [][]string
+	var recordOf []int
+	for i, record := range records {
+		for _, loc := range re.FindAllStringSubmatchIndex(text[record.Start:record.End], -1) {
+			groups := make([]string, len(loc)/2)
+			for g := range groups {
+				if loc[2*g] >= 0 {
+					groups[g] = text[record.Start+loc[2*g] : record.Start+loc[2*g+1]]
+				}
+			}
+			indices = append(indices, []int{record.Start + loc[0], record.Start + loc[1]})
+			matches = append(matches, groups)
+			recordOf = append(recordOf, i)
+		}
+	}
+	return indices, matches, recordOf, nil
+}
@@ -0,0 +1,434 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RefactorResult holds a rewritten pattern and how capture group numbers changed.
+type RefactorResult struct {
+	Pattern string
+	// GroupMap maps each old group number to its new number. Groups that no longer exist are absent.
+	GroupMap map[int]int
+}
+
+// parseForRefactor validates the pattern and returns its structural tree.
+func parseForRefactor(pattern string) (*patternNode, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern is empty")
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, err
+	}
+	return parsePatternTree(pattern)
+}
+
+// captureCount returns the number of capturing groups in the tree.
+func captureCount(root *patternNode) int {
+	count := 0
+	walkGroups(root, func(n *patternNode) {
+		if n.tok.Capturing {
+			count++
+		}
+	})
+	return count
+}
+
+// identityGroupMap maps every group of the tree to itself.
+func identityGroupMap(root *patternNode) map[int]int {
+	m := map[int]int{0: 0}
+	for i := 1; i <= captureCount(root); i++ {
+		m[i] = i
+	}
+	return m
+}
+
+// RefactorToNonCapturing turns every capturing group, named or not, into a non-capturing group.
+func RefactorToNonCapturing(pattern string) (RefactorResult, error) {
+	root, err := parseForRefactor(pattern)
+	if err != nil {
+		return RefactorResult{}, err
+	}
+	walkGroups(root, func(n *patternNode) {
+		if n.tok.Capturing {
+			n.tok = patternToken{Kind: tokGroupOpen, Text: "(?:"}
+		}
+	})
+	return RefactorResult{Pattern: renderPattern(root), GroupMap: map[int]int{0: 0}}, nil
+}
+
+// RefactorToCapturing turns every plain non-capturing group `(?:...)` into a capturing group.
+// Groups with flags such as `(?i:...)` are left alone.
+func RefactorToCapturing(pattern string) (RefactorResult, error) {
+	root, err := parseForRefactor(pattern)
+	if err != nil {
+		return RefactorResult{}, err
+	}
+	groupMap := map[int]int{0: 0}
+	oldNum, newNum := 0, 0
+	walkGroups(root, func(n *patternNode) {
+		switch {
+		case n.tok.Capturing:
+			oldNum++
+			newNum++
+			groupMap[oldNum] = newNum
+		case n.tok.Text == "(?:":
+			n.tok = patternToken{Kind: tokGroupOpen, Text: "(", Capturing: true}
+			newNum++
+		}
+	})
+	return RefactorResult{Pattern: renderPattern(root), GroupMap: groupMap}, nil
+}
+
+// RefactorNameGroups gives every unnamed capturing group a `(?P<gN>...)` name, where N is its group number.
+func RefactorNameGroups(pattern string) (RefactorResult, error) {
+	root, err := parseForRefactor(pattern)
+	if err != nil {
+		return RefactorResult{}, err
+	}
+	used := map[string]bool{}
+	walkGroups(root, func(n *patternNode) {
+		if n.tok.Name != "" {
+			used[n.tok.Name] = true
+		}
+	})
+	num := 0
+	walkGroups(root, func(n *patternNode) {
+		if !n.tok.Capturing {
+			return
+		}
+		num++
+		if n.tok.Name != "" {
+			return
+		}
+		name := fmt.Sprintf("g%d", num)
+		for used[name] {
+			name += "_"
+		}
+		used[name] = true
+		n.tok = patternToken{Kind: tokGroupOpen, Text: "(?P<" + name + ">", Capturing: true, Name: name}
+	})
+	return RefactorResult{Pattern: renderPattern(root), GroupMap: identityGroupMap(root)}, nil
+}
+
+// RefactorFactorAlternations rewrites alternations whose branches are plain literals.
+// Single characters become a character class (`a|b|c` -> `[abc]`) and longer literals
+// have their common prefix factored out (`foo|foobar|fob` -> `fo(?:o(?:bar)?|b)`).
+func RefactorFactorAlternations(pattern string) (RefactorResult, error) {
+	root, err := parseForRefactor(pattern)
+	if err != nil {
+		return RefactorResult{}, err
+	}
+	// Branches are regrouped by their first character, which is only safe while letters don't fold.
+	if caseInsensitiveRe.MatchString(pattern) {
+		return RefactorResult{}, fmt.Errorf("alternations cannot be factored in case-insensitive patterns")
+	}
+	factorNode(root)
+	return RefactorResult{Pattern: renderPattern(root), GroupMap: identityGroupMap(root)}, nil
+}
+
+var caseInsensitiveRe = regexp.MustCompile(`\(\?[a-zA-Z]*i[a-zA-Z-]*[:)]`)
+
+func factorNode(node *patternNode) {
+	for _, branch := range node.branches {
+		for _, child := range branch {
+			if child.isGroup() {
+				factorNode(child)
+			}
+		}
+	}
+	if len(node.branches) < 2 {
+		return
+	}
+	literals := make([][]string, len(node.branches))
+	for i, branch := range node.branches {
+		for _, child := range branch {
+			if child.tok.Kind != tokLiteral {
+				return
+			}
+			literals[i] = append(literals[i], child.tok.Text)
+		}
+	}
+	node.branches = [][]*patternNode{{{tok: patternToken{Kind: tokLiteral, Text: factorLiterals(literals)}}}}
+}
+
+// factorLiterals renders an alternation of literal token sequences as compact pattern source.
+// Go regexps prefer the leftmost alternative, so the rewrite never changes which alternative wins:
+// only branches that cannot match at the same position are reordered.
+func factorLiterals(literals [][]string) string {
+	// Remove duplicates while keeping order, as a repeated branch can never win.
+	var unique [][]string
+	for _, lit := range literals {
+		if !slices.ContainsFunc(unique, func(u []string) bool { return slices.Equal(u, lit) }) {
+			unique = append(unique, lit)
+		}
+	}
+	if len(unique) == 1 {
+		return strings.Join(unique[0], "")
+	}
+
+	prefixLen := 0
+	for prefixLen < len(unique[0]) && !slices.ContainsFunc(unique[1:], func(lit []string) bool {
+		return prefixLen >= len(lit) || lit[prefixLen] != unique[0][prefixLen]
+	}) {
+		prefixLen++
+	}
+	if prefixLen > 0 {
+		rests := make([][]string, len(unique))
+		for i, lit := range unique {
+			rests[i] = lit[prefixLen:]
+		}
+		rest := factorLiterals(rests)
+		if hasTopLevelAlt(rest) {
+			rest = "(?:" + rest + ")"
+		}
+		return strings.Join(unique[0][:prefixLen], "") + rest
+	}
+
+	emptyAt := slices.IndexFunc(unique, func(lit []string) bool { return len(lit) == 0 })
+	switch emptyAt {
+	case -1:
+		return factorByFirstToken(unique)
+	case 0, len(unique) - 1:
+		// An empty branch first or last is an optional group, lazy if the empty branch was preferred.
+		rest := factorByFirstToken(slices.Delete(slices.Clone(unique), emptyAt, emptyAt+1))
+		if !isSingleAtom(rest) {
+			rest = "(?:" + rest + ")"
+		}
+		if emptyAt == 0 {
+			return rest + "??"
+		}
+		return rest + "?"
+	default:
+		// An empty branch in the middle competes with both sides; leave this level as it is.
+		parts := make([]string, len(unique))
+		for i, lit := range unique {
+			parts[i] = strings.Join(lit, "")
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// factorByFirstToken groups non-empty branches by their first token and factors each group.
+// Branches starting with different characters are mutually exclusive, so grouping them is safe.
+func factorByFirstToken(literals [][]string) string {
+	if len(literals) == 1 {
+		return strings.Join(literals[0], "")
+	}
+	var order []string
+	groups := map[string][][]string{}
+	for _, lit := range literals {
+		if _, ok := groups[lit[0]]; !ok {
+			order = append(order, lit[0])
+		}
+		groups[lit[0]] = append(groups[lit[0]], lit)
+	}
+	var singles, parts []string
+	for _, first := range order {
+		group := groups[first]
+		if len(group) == 1 && len(group[0]) == 1 {
+			singles = append(singles, first)
+			continue
+		}
+		parts = append(parts, factorLiterals(group))
+	}
+	switch len(singles) {
+	case 0:
+	case 1:
+		parts = append(parts, singles[0])
+	default:
+		parts = append(parts, literalClass(singles))
+	}
+	return strings.Join(parts, "|")
+}
+
+// isSingleAtom reports whether the pattern is one token that a quantifier can apply to directly.
+func isSingleAtom(pattern string) bool {
+	tokens, err := tokenizePattern(pattern)
+	return err == nil && len(tokens) == 1 && tokens[0].Kind != tokQuant
+}
+
+// hasTopLevelAlt reports whether the pattern contains a `|` outside of any group.
+func hasTopLevelAlt(pattern string) bool {
+	root, err := parsePatternTree(pattern)
+	return err == nil && len(root.branches) > 1
+}
+
+// literalClass builds a character class from single literal tokens.
+func literalClass(literals []string) string {
+	var builder strings.Builder
+	builder.WriteString("[")
+	for _, lit := range literals {
+		if len(lit) == 1 && strings.ContainsAny(lit, `]-^\[`) {
+			builder.WriteString(`\`)
+		}
+		builder.WriteString(lit)
+	}
+	builder.WriteString("]")
+	return builder.String()
+}
+
+// RefactorMinify shortens the pattern without changing what it matches:
+// redundant non-capturing groups are unwrapped, counted repetitions use their short forms
+// and character classes equal to `\d`, `\w` or `\s` are replaced by the escape.
+func RefactorMinify(pattern string) (RefactorResult, error) {
+	root, err := parseForRefactor(pattern)
+	if err != nil {
+		return RefactorResult{}, err
+	}
+	minifyNode(root)
+	return RefactorResult{Pattern: renderPattern(root), GroupMap: identityGroupMap(root)}, nil
+}
+
+var shortRepeats = map[string]string{
+	"{0,}": "*", "{1,}": "+", "{0,1}": "?", "{1}": "",
+	"{0,}?": "*?", "{1,}?": "+?", "{0,1}?": "??", "{1}?": "",
+}
+
+func minifyNode(node *patternNode) {
+	for bi, branch := range node.branches {
+		var out []*patternNode
+		for i, child := range branch {
+			quantified := i+1 < len(branch) && branch[i+1].tok.Kind == tokQuant
+			switch {
+			case child.isGroup():
+				minifyNode(child)
+				// Flags like (?i) apply up to the end of their group, so a group holding them is kept.
+				if child.tok.Text == "(?:" && len(child.branches) == 1 && !slices.ContainsFunc(child.branches[0], func(n *patternNode) bool {
+					return n.tok.Kind == tokFlags
+				}) {
+					inner := child.branches[0]
+					if len(inner) == 0 && !quantified {
+						continue
+					}
+					if !quantified || len(inner) == 1 && inner[0].tok.Kind != tokQuant {
+						out = append(out, inner...)
+						continue
+					}
+				}
+			case child.tok.Kind == tokQuant:
+				if short, ok := shortRepeats[child.tok.Text]; ok {
+					if short == "" {
+						continue
+					}
+					child.tok.Text = short
+				}
+			case child.tok.Kind == tokClass:
+				child.tok.Text = minifyClass(child.tok.Text)
+			}
+			out = append(out, child)
+		}
+		node.branches[bi] = out
+	}
+}
+
+var classShorthands = []string{`\d`, `\D`, `\w`, `\W`, `\s`, `\S`}
+
+// minifyClass replaces a bracketed class by an equivalent Perl shorthand when one exists.
+func minifyClass(class string) string {
+	re, err := syntax.Parse(class, syntax.Perl)
+	if err != nil || re.Op != syntax.OpCharClass {
+		return class
+	}
+	for _, short := range classShorthands {
+		sre, _ := syntax.Parse(short, syntax.Perl)
+		if slices.Equal(sre.Rune, re.Rune) && len(short) < len(class) {
+			return short
+		}
+	}
+	return class
+}
+
+// PrettyPattern renders the pattern as an indented tree, one group per level,
+// so that deeply nested patterns can be read. RE2 has no extended mode, so the
+// result is for display only.
+func PrettyPattern(pattern string) (string, error) {
+	root, err := parseForRefactor(pattern)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	prettyBranches(&builder, root.branches, 0)
+	return strings.TrimRight(builder.String(), "\n"), nil
+}
+
+func prettyBranches(builder *strings.Builder, branches [][]*patternNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for bi, branch := range branches {
+		if bi > 0 {
+			builder.WriteString(strings.Repeat("  ", max(depth-1, 0)) + "|\n")
+		}
+		var line strings.Builder
+		flush := func() {
+			if line.Len() > 0 {
+				builder.WriteString(indent + line.String() + "\n")
+				line.Reset()
+			}
+		}
+		for i := 0; i < len(branch); i++ {
+			child := branch[i]
+			if !child.isGroup() {
+				line.WriteString(child.tok.Text)
+				continue
+			}
+			flush()
+			builder.WriteString(indent + child.tok.Text + "\n")
+			prettyBranches(builder, child.branches, depth+1)
+			closing := ")"
+			// Keep a quantifier on the same line as the group it applies to.
+			if i+1 < len(branch) && branch[i+1].tok.Kind == tokQuant {
+				closing += branch[i+1].tok.Text
+				i++
+			}
+			builder.WriteString(indent + closing + "\n")
+		}
+		flush()
+	}
+}
+
+var groupRefRe = regexp.MustCompile(`\$(\d+)`)
+
+// RemapCustomFormat rewrites the `$N` placeholders of a custom export format after the groups were renumbered.
+// If a placeholder refers to a group that no longer exists, the format is returned unchanged with an error.
+func RemapCustomFormat(format string, groupMap map[int]int) (string, error) {
+	var removed []string
+	remapped := groupRefRe.ReplaceAllStringFunc(format, func(ref string) string {
+		old, _ := strconv.Atoi(ref[1:])
+		if n, ok := groupMap[old]; ok {
+			return "$" + strconv.Itoa(n)
+		}
+		if !slices.Contains(removed, ref) {
+			removed = append(removed, ref)
+		}
+		return ref
+	})
+	if len(removed) > 0 {
+		return format, fmt.Errorf("the custom format refers to groups that no longer exist (%s) and was left unchanged", strings.Join(removed, ", "))
+	}
+	return remapped, nil
+}
+
+// RemapGroupNumbers rewrites a comma-separated group number list after the groups were renumbered.
+// Numbers of groups that no longer exist are dropped; entries that are not numbers are kept.
+func RemapGroupNumbers(input string, groupMap map[int]int) string {
+	if strings.TrimSpace(input) == "" {
+		return input
+	}
+	var out []string
+	for _, s := range strings.Split(input, ",") {
+		s = strings.TrimSpace(s)
+		old, err := strconv.Atoi(s)
+		if err != nil {
+			out = append(out, s)
+			continue
+		}
+		if n, ok := groupMap[old]; ok {
+			out = append(out, strconv.Itoa(n))
+		}
+	}
+	return strings.Join(out, ",")
+}
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ScanResult is a regex literal found in Go source, or a file that could not be parsed.
+type ScanResult struct {
+	File    string
+	Line    int
+	Func    string // The regexp function that receives the pattern, e.g. "MustCompile", empty for a parse error.
+	Pattern string
+	Err     error // Compile error of the pattern or parse error of the file, nil if it is valid.
+}
+
+// ParseError reports whether the result is a file that could not be parsed rather than a pattern.
+func (r ScanResult) ParseError() bool {
+	return r.Func == ""
+}
+
+// Location returns the position of the call in file:line form.
+func (r ScanResult) Location() string {
+	return fmt.Sprintf("%s:%d", r.File, r.Line)
+}
+
+// scannedFuncs are the regexp functions whose first argument is a pattern.
+var scannedFuncs = map[string]bool{
+	"MustCompile": true, "Compile": true, "MustCompilePOSIX": true, "CompilePOSIX": true,
+	"MatchString": true, "Match": true, "MatchReader": true,
+}
+
+// ScanGoSources finds the regexp calls with constant patterns in Go source files.
+// Each argument is a file, a directory, or a directory followed by "/..." to include its subdirectories,
+// following the conventions of the go tool. With no arguments the current directory is scanned.
+func ScanGoSources(args []string) ([]ScanResult, error) {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	var dirs []string
+	var files []string
+	for _, arg := range args {
+		if root, ok := strings.CutSuffix(arg, "..."); ok {
+			root = filepath.Clean(strings.TrimSuffix(root, "/"))
+			if root == "" {
+				root = "."
+			}
+			err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					name := d.Name()
+					if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+						return filepath.SkipDir
+					}
+					dirs = append(dirs, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, arg)
+		} else {
+			files = append(files, arg)
+		}
+	}
+
+	var results []ScanResult
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		var dirFiles []string
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+				dirFiles = append(dirFiles, filepath.Join(dir, entry.Name()))
+			}
+		}
+		found, err := scanPackageFiles(dirFiles)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+	for _, file := range files {
+		found, err := scanPackageFiles([]string{file})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+	return results, nil
+}
+
+// scanPackageFiles scans files of one package so that constants declared in one file
+// can be resolved in another. A file that can't be parsed is reported as a result and
+// the other files are still scanned.
+func scanPackageFiles(paths []string) ([]ScanResult, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	var results []ScanResult
+	for _, path := range paths {
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			result := ScanResult{File: path, Err: err}
+			var list scanner.ErrorList
+			if errors.As(err, &list) && len(list) > 0 {
+				result.Line, result.Err = list[0].Pos.Line, errors.New(list[0].Msg)
+			}
+			results = append(results, result)
+			continue
+		}
+		files = append(files, file)
+	}
+
+	consts := map[string]ast.Expr{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i < len(vs.Values) {
+						consts[name.Name] = vs.Values[i]
+					}
+				}
+			}
+		}
+	}
+
+	for _, file := range files {
+		regexpName := importName(file, "regexp")
+		if regexpName == "" {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || !scannedFuncs[sel.Sel.Name] {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != regexpName {
+				return true
+			}
+			pattern, ok := constantString(call.Args[0], consts, 0)
+			if !ok {
+				return true
+			}
+
+			result := ScanResult{
+				File:    fset.Position(call.Pos()).Filename,
+				Line:    fset.Position(call.Pos()).Line,
+				Func:    sel.Sel.Name,
+				Pattern: pattern,
+			}
+			if strings.HasSuffix(sel.Sel.Name, "POSIX") {
+				_, result.Err = regexp.CompilePOSIX(pattern)
+			} else {
+				_, result.Err = regexp.Compile(pattern)
+			}
+			results = append(results, result)
+			return true
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].File != results[j].File {
+			return results[i].File < results[j].File
+		}
+		return results[i].Line < results[j].Line
+	})
+	return results, nil
+}
+
+// importName returns the name under which the file imports the package, or "" if it doesn't.
+func importName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == path {
+			if spec.Name != nil {
+				if spec.Name.Name == "_" || spec.Name.Name == "." {
+					return ""
+				}
+				return spec.Name.Name
+			}
+			return filepath.Base(path)
+		}
+	}
+	return ""
+}
+
+// constantString evaluates string literals, their concatenation and references to string constants.
+func constantString(expr ast.Expr, consts map[string]ast.Expr, depth int) (string, bool) {
+	// Guard against constants that refer to each other.
+	if depth > 32 {
+		return "", false
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.ParenExpr:
+		return constantString(e.X, consts, depth+1)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		left, ok := constantString(e.X, consts, depth+1)
+		if !ok {
+			return "", false
+		}
+		right, ok := constantString(e.Y, consts, depth+1)
+		return left + right, ok
+	case *ast.Ident:
+		if value, ok := consts[e.Name]; ok {
+			return constantString(value, consts, depth+1)
+		}
+	}
+	return "", false
+}
+
+// FormatScanResults renders the results as one line per pattern, like compiler diagnostics.
+func FormatScanResults(results []ScanResult) string {
+	var builder strings.Builder
+	for _, r := range results {
+		if r.ParseError() {
+			fmt.Fprintf(&builder, "%s: parse error: %v\n", r.Location(), r.Err)
+			continue
+		}
+		status := "ok"
+		if r.Err != nil {
+			status = "error: " + r.Err.Error()
+		}
+		fmt.Fprintf(&builder, "%s: %s(%s) %s\n", r.Location(), r.Func, strconv.Quote(r.Pattern), status)
+	}
+	return builder.String()
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"regexp"
+)
+
+// SplitOptions configures split mode, in which the matches of the pattern delimit fields.
+type SplitOptions struct {
+	Enabled        bool
+	Limit          int  // Maximum number of fields per record, 0 for no limit.
+	KeepDelimiters bool // Whether the delimiters are kept as fields of their own.
+}
+
+// SplitFields splits each unit of the text, a record or the whole text, at the matches of
+// the pattern like regexp.Split, and returns the fields of each unit.
+func SplitFields(regexStr, text string, units []Span, opts SplitOptions) ([][]string, error) {
+	re, err := regexp.Compile(regexStr)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]string, len(units))
+	for i, unit := range units {
+		rows[i] = splitUnit(re, text[unit.Start:unit.End], opts)
+	}
+	return rows, nil
+}
+
+// splitUnit follows regexp.Split, with n = opts.Limit, and inserts the delimiters between
+// the fields if they are kept.
+func splitUnit(re *regexp.Regexp, s string, opts SplitOptions) []string {
+	if s == "" {
+		return []string{""}
+	}
+	n := opts.Limit
+	if n <= 0 {
+		n = -1
+	}
+	var fields []string
+	count := 0
+	begin, end := 0, 0
+	for _, match := range re.FindAllStringIndex(s, n) {
+		if n > 0 && count == n-1 {
+			break
+		}
+		end = match[0]
+		if match[1] != 0 {
+			fields = append(fields, s[begin:end])
+			count++
+			if opts.KeepDelimiters {
+				fields = append(fields, s[match[0]:match[1]])
+			}
+		}
+		begin = match[1]
+	}
+	if end != len(s) {
+		fields = append(fields, s[begin:])
+	}
+	return fields
+}
+
+type exportFieldsJson struct {
+	Regex  string     `json:"regex"`
+	Fields [][]string `json:"fields"`
+}
+
+// GenerateFieldsExportJSON generates JSON with an array of fields per record. If
+// groupInput is set, only these fields are exported, numbered from 1.
+func GenerateFieldsExportJSON(regexStr string, rows [][]string, groupInput string) ([]byte, error) {
+	data := exportFieldsJson{Regex: regexStr, Fields: rows}
+	if groupInput != "" {
+		numbers, err := parseGroupNumbers(groupInput)
+		if err != nil {
+			return nil, err
+		}
+		data.Fields = make([][]string, len(rows))
+		for i, row := range rows {
+			data.Fields[i] = []string{}
+			for _, n := range numbers {
+				if n >= 1 && n <= len(row) {
+					data.Fields[i] = append(data.Fields[i], row[n-1])
+				}
+			}
+		}
+	}
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// GenerateFieldsExportCSV generates a CSV line per record, with a column per field.
+func GenerateFieldsExportCSV(rows [][]string) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// FieldMatches returns the fields of each record in the shape of matches, the record
+// first, so that the custom format refers to fields as $1, $2 and to the record as $0.
+func FieldMatches(text string, units []Span, rows [][]string) [][]string {
+	matches := make([][]string, len(rows))
+	for i, row := range rows {
+		matches[i] = append([]string{text[units[i].Start:units[i].End]}, row...)
+	}
+	return matches
+}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Units of the numbers summarised by NumericStats.
+const (
+	UnitNone = ""
+	UnitTime = "ms" // Durations, in milliseconds.
+	UnitSize = "B"  // Sizes, in bytes.
+)
+
+var sizeRe = regexp.MustCompile(`(?i)^([-+]?(?:\d+\.?\d*|\.\d+))\s*([KMGT]i?B|B)$`)
+
+var sizeFactors = map[string]float64{"": 1, "K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+
+// ParseQuantity parses a number, a duration such as 12ms, 1.5s or 1m30s, or a size such
+// as 512B, 2KB or 1.5MiB. Durations are returned in milliseconds and sizes in bytes, with
+// binary multiples.
+func ParseQuantity(s string) (float64, string, bool) {
+	s = strings.TrimSpace(s)
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, UnitNone, !math.IsNaN(v) && !math.IsInf(v, 0)
+	}
+	if d, err := time.ParseDuration(strings.ReplaceAll(s, " ", "")); err == nil {
+		return float64(d) / float64(time.Millisecond), UnitTime, true
+	}
+	if m := sizeRe.FindStringSubmatch(s); m != nil {
+		v, _ := strconv.ParseFloat(m[1], 64)
+		prefix := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(m[2]), "B"), "I")
+		return v * sizeFactors[prefix], UnitSize, true
+	}
+	return 0, UnitNone, false
+}
+
+// HistogramBin counts the values in [Low, High), the last bin including its High.
+type HistogramBin struct {
+	Low   float64
+	High  float64
+	Count int
+}
+
+// Stats summarises the numbers of a group.
+type Stats struct {
+	Count     int
+	Skipped   int    // Values that are not numbers, or not in the unit of the first number.
+	Unit      string // UnitNone, UnitTime or UnitSize.
+	Sum       float64
+	Min       float64
+	Max       float64
+	Mean      float64
+	Median    float64
+	P90       float64
+	P95       float64
+	P99       float64
+	Histogram []HistogramBin
+}
+
+// histogramBins is the number of bins of the histogram.
+const histogramBins = 10
+
+// NumericStats summarises the values of a group, 0 for the whole match. The first number
+// sets the unit; values in another unit are skipped.
+func NumericStats(matches [][]string, group int) Stats {
+	var stats Stats
+	var values []float64
+	for _, match := range matches {
+		if group >= len(match) {
+			continue
+		}
+		v, unit, ok := ParseQuantity(match[group])
+		if ok && len(values) == 0 {
+			stats.Unit = unit
+		}
+		if !ok || unit != stats.Unit {
+			stats.Skipped++
+			continue
+		}
+		values = append(values, v)
+	}
+	stats.Count = len(values)
+	if stats.Count == 0 {
+		return stats
+	}
+
+	sort.Float64s(values)
+	for _, v := range values {
+		stats.Sum += v
+	}
+	stats.Min, stats.Max = values[0], values[len(values)-1]
+	stats.Mean = stats.Sum / float64(len(values))
+	stats.Median = values[len(values)/2]
+	if len(values)%2 == 0 {
+		stats.Median = (values[len(values)/2-1] + values[len(values)/2]) / 2
+	}
+	// Nearest-rank percentiles.
+	percentile := func(p float64) float64 {
+		return values[int(math.Ceil(p/100*float64(len(values))))-1]
+	}
+	stats.P90, stats.P95, stats.P99 = percentile(90), percentile(95), percentile(99)
+
+	bins := histogramBins
+	if stats.Min == stats.Max {
+		bins = 1
+	}
+	width := (stats.Max - stats.Min) / float64(bins)
+	stats.Histogram = make([]HistogramBin, bins)
+	for i := range stats.Histogram {
+		stats.Histogram[i] = HistogramBin{Low: stats.Min + float64(i)*width, High: stats.Min + float64(i+1)*width}
+	}
+	stats.Histogram[bins-1].High = stats.Max
+	for _, v := range values {
+		i := bins - 1
+		if width > 0 {
+			i = min(int((v-stats.Min)/width), bins-1)
+		}
+		stats.Histogram[i].Count++
+	}
+	return stats
+}
+
+// formatQuantity formats a number with up to 3 decimals and the unit.
+func formatQuantity(v float64, unit string) string {
+	s := strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+	if unit != UnitNone {
+		s += " " + unit
+	}
+	return s
+}
+
+// RenderStats formats the statistics, followed by the histogram.
+func RenderStats(stats Stats) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("count   %d", stats.Count))
+	if stats.Skipped > 0 {
+		builder.WriteString(fmt.Sprintf(" (%d skipped)", stats.Skipped))
+	}
+	builder.WriteString("\n")
+	if stats.Count == 0 {
+		return builder.String()
+	}
+	for _, line := range []struct {
+		name  string
+		value float64
+	}{
+		{"sum", stats.Sum}, {"min", stats.Min}, {"max", stats.Max}, {"mean", stats.Mean},
+		{"median", stats.Median}, {"p90", stats.P90}, {"p95", stats.P95}, {"p99", stats.P99},
+	} {
+		builder.WriteString(fmt.Sprintf("%-7s %s\n", line.name, formatQuantity(line.value, stats.Unit)))
+	}
+
+	builder.WriteString("\n")
+	most, width := 0, 0
+	labels := make([]string, len(stats.Histogram))
+	for i, bin := range stats.Histogram {
+		most = max(most, bin.Count)
+		labels[i] = formatQuantity(bin.Low, stats.Unit) + " - " + formatQuantity(bin.High, stats.Unit)
+		width = max(width, len(labels[i]))
+	}
+	for i, bin := range stats.Histogram {
+		length := bin.Count * freqBarWidth / most
+		bar := strings.Repeat("█", length)
+		if bin.Count > 0 && length == 0 {
+			bar, length = "▏", 1
+		}
+		bar += strings.Repeat(" ", freqBarWidth-length)
+		builder.WriteString(fmt.Sprintf("%-*s %s %d\n", width, labels[i], bar, bin.Count))
+	}
+	return builder.String()
+}
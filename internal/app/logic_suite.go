@@ -0,0 +1,154 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CaptureCase is a sample string together with the groups its first match must capture.
+// Groups are keyed by group number ("0" is the whole match) or by group name.
+type CaptureCase struct {
+	Input  string            `json:"input"`
+	Groups map[string]string `json:"groups"`
+}
+
+// TestSuite pairs a pattern with strings it must and must not match.
+type TestSuite struct {
+	Name     string        `json:"name"`
+	Pattern  string        `json:"pattern"`
+	Match    []string      `json:"match"`
+	NoMatch  []string      `json:"noMatch"`
+	Captures []CaptureCase `json:"captures"`
+}
+
+// SuiteFile is the content of a test suite file.
+type SuiteFile struct {
+	Suites []TestSuite `json:"suites"`
+}
+
+// LoadSuiteFile reads a test suite file. Every suite must have a pattern.
+func LoadSuiteFile(path string) (SuiteFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SuiteFile{}, err
+	}
+	var file SuiteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return SuiteFile{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(file.Suites) == 0 {
+		return SuiteFile{}, fmt.Errorf("%s: no suites defined", path)
+	}
+	for i, suite := range file.Suites {
+		if suite.Pattern == "" {
+			name := strconv.Quote(suite.Name)
+			if suite.Name == "" {
+				name = "#" + strconv.Itoa(i+1)
+			}
+			return SuiteFile{}, fmt.Errorf("%s: suite %s has no pattern", path, name)
+		}
+	}
+	return file, nil
+}
+
+// Suite returns the suite with the given name, or the first suite if the name is empty.
+func (f SuiteFile) Suite(name string) (TestSuite, error) {
+	if name == "" {
+		return f.Suites[0], nil
+	}
+	names := make([]string, len(f.Suites))
+	for i, suite := range f.Suites {
+		if suite.Name == name {
+			return suite, nil
+		}
+		names[i] = strconv.Quote(suite.Name)
+	}
+	return TestSuite{}, fmt.Errorf("no suite named %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// CaseResult is the outcome of one sample of a suite.
+type CaseResult struct {
+	Kind   string // "match", "noMatch" or "capture".
+	Input  string
+	Passed bool
+	Detail string // Why the case failed.
+}
+
+// RunSuite checks the samples of the suite against the given pattern, which is usually
+// the suite's own pattern but may be a pattern that is being edited.
+func RunSuite(suite TestSuite, pattern string) ([]CaseResult, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []CaseResult
+	for _, input := range suite.Match {
+		result := CaseResult{Kind: "match", Input: input, Passed: re.MatchString(input)}
+		if !result.Passed {
+			result.Detail = "did not match"
+		}
+		results = append(results, result)
+	}
+	for _, input := range suite.NoMatch {
+		result := CaseResult{Kind: "noMatch", Input: input, Passed: true}
+		if loc := re.FindStringIndex(input); loc != nil {
+			result.Passed = false
+			result.Detail = fmt.Sprintf("matched %q", input[loc[0]:loc[1]])
+		}
+		results = append(results, result)
+	}
+	for _, c := range suite.Captures {
+		results = append(results, checkCaptures(re, c))
+	}
+	return results, nil
+}
+
+func checkCaptures(re *regexp.Regexp, c CaptureCase) CaseResult {
+	result := CaseResult{Kind: "capture", Input: c.Input}
+	match := re.FindStringSubmatch(c.Input)
+	if match == nil {
+		result.Detail = "did not match"
+		return result
+	}
+
+	keys := make([]string, 0, len(c.Groups))
+	for key := range c.Groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			index = re.SubexpIndex(key)
+		}
+		if index < 0 || index >= len(match) {
+			problems = append(problems, fmt.Sprintf("group %s does not exist", key))
+			continue
+		}
+		if match[index] != c.Groups[key] {
+			problems = append(problems, fmt.Sprintf("group %s: expected %q, got %q", key, c.Groups[key], match[index]))
+		}
+	}
+	result.Passed = len(problems) == 0
+	result.Detail = strings.Join(problems, "; ")
+	return result
+}
+
+// CountFailures returns the number of failed cases.
+func CountFailures(results []CaseResult) int {
+	failures := 0
+	for _, r := range results {
+		if !r.Passed {
+			failures++
+		}
+	}
+	return failures
+}
@@ -0,0 +1,378 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Span is a marked byte range of the text.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Candidate is a synthesized pattern together with the number of matches it has in the text.
+type Candidate struct {
+	Pattern string
+	Matches int
+}
+
+// segmentKind classifies a run of characters in an example.
+type segmentKind int
+
+const (
+	segDigits segmentKind = iota
+	segLetters
+	segWord // Letters, digits and underscores, used by the coarse split.
+	segSpace
+	segOther // A single punctuation or symbol character.
+)
+
+// segment is a run of characters of the same kind.
+type segment struct {
+	kind segmentKind
+	text string
+}
+
+// Synthesis levels, from the most specific to the loosest.
+const (
+	levelPrecise = iota // Exact lengths and letter case.
+	levelGeneral        // Any length, letter case kept.
+	levelLoose          // Only delimiters are kept literally.
+)
+
+// maxCandidates is the number of candidates returned by Synthesize.
+const maxCandidates = 8
+
+// Synthesize proposes patterns that match every positive span of the text exactly and
+// don't match inside any negative span. The candidates are ordered from the simplest,
+// counting pattern tokens, to the most complex; among equally simple ones, those with
+// fewer matches in the text come first.
+func Synthesize(text string, positives, negatives []Span) ([]Candidate, error) {
+	if len(positives) == 0 {
+		return nil, fmt.Errorf("mark at least one positive example")
+	}
+	examples := make([]string, len(positives))
+	for i, span := range positives {
+		if span.Start < 0 || span.End > len(text) || span.Start >= span.End {
+			return nil, fmt.Errorf("example %d is outside the text", i+1)
+		}
+		examples[i] = text[span.Start:span.End]
+	}
+
+	var patterns []string
+	for _, split := range []func(string) []segment{splitFine, splitCoarse} {
+		segments, ok := alignSegments(examples, split)
+		if !ok {
+			continue
+		}
+		for _, level := range []int{levelPrecise, levelGeneral, levelLoose} {
+			patterns = append(patterns, renderSegments(segments, level))
+		}
+	}
+	patterns = append(patterns, delimitedPattern(examples), alternationPattern(examples))
+
+	seen := map[string]bool{}
+	type scored struct {
+		Candidate
+		score int
+	}
+	var found []scored
+	for _, pattern := range patterns {
+		if pattern == "" || seen[pattern] {
+			continue
+		}
+		seen[pattern] = true
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+		matches := re.FindAllStringIndex(text, -1)
+		if !coversSpans(matches, positives) || overlapsSpans(matches, negatives) {
+			continue
+		}
+		tokens, err := tokenizePattern(pattern)
+		if err != nil {
+			continue
+		}
+		found = append(found, scored{Candidate{Pattern: pattern, Matches: len(matches)}, len(tokens)})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score < found[j].score
+		}
+		if found[i].Matches != found[j].Matches {
+			return found[i].Matches < found[j].Matches
+		}
+		return len(found[i].Pattern) < len(found[j].Pattern)
+	})
+	var candidates []Candidate
+	for i := 0; i < len(found) && i < maxCandidates; i++ {
+		candidates = append(candidates, found[i].Candidate)
+	}
+	return candidates, nil
+}
+
+// coversSpans reports whether every span is exactly one of the matches.
+func coversSpans(matches [][]int, spans []Span) bool {
+	for _, span := range spans {
+		found := false
+		for _, m := range matches {
+			if m[0] == span.Start && m[1] == span.End {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapsSpans reports whether any match touches any of the spans.
+func overlapsSpans(matches [][]int, spans []Span) bool {
+	for _, span := range spans {
+		for _, m := range matches {
+			if m[0] < span.End && m[1] > span.Start {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitFine splits an example into digit runs, letter runs, whitespace runs and single other characters.
+func splitFine(s string) []segment {
+	return splitRuns(s, func(r rune) segmentKind {
+		switch {
+		case unicode.IsDigit(r):
+			return segDigits
+		case unicode.IsLetter(r):
+			return segLetters
+		case unicode.IsSpace(r):
+			return segSpace
+		}
+		return segOther
+	})
+}
+
+// splitCoarse splits an example into word runs, whitespace runs and single other characters.
+func splitCoarse(s string) []segment {
+	return splitRuns(s, func(r rune) segmentKind {
+		switch {
+		case r == '_' || unicode.IsDigit(r) || unicode.IsLetter(r):
+			return segWord
+		case unicode.IsSpace(r):
+			return segSpace
+		}
+		return segOther
+	})
+}
+
+func splitRuns(s string, classify func(rune) segmentKind) []segment {
+	var segments []segment
+	for i, r := range s {
+		kind := classify(r)
+		n := len(segments)
+		if n > 0 && kind != segOther && segments[n-1].kind == kind {
+			segments[n-1].text += string(r)
+			continue
+		}
+		segments = append(segments, segment{kind: kind, text: s[i : i+utf8.RuneLen(r)]})
+	}
+	return segments
+}
+
+// alignSegments splits every example and returns, per position, the texts found there.
+// The examples align if they have the same sequence of kinds and the same delimiters.
+func alignSegments(examples []string, split func(string) []segment) ([][]segment, bool) {
+	var columns [][]segment
+	for i, example := range examples {
+		segments := split(example)
+		if i == 0 {
+			columns = make([][]segment, len(segments))
+		} else if len(segments) != len(columns) {
+			return nil, false
+		}
+		for j, seg := range segments {
+			if i > 0 {
+				first := columns[j][0]
+				if first.kind != seg.kind || (seg.kind == segOther && first.text != seg.text) {
+					return nil, false
+				}
+			}
+			columns[j] = append(columns[j], seg)
+		}
+	}
+	return columns, true
+}
+
+// renderSegments generalises each column of aligned segments according to the level.
+func renderSegments(columns [][]segment, level int) string {
+	var builder strings.Builder
+	for _, column := range columns {
+		kind := column[0].kind
+		same := true
+		minLen, maxLen := -1, 0
+		for _, seg := range column {
+			same = same && seg.text == column[0].text
+			n := utf8.RuneCountInString(seg.text)
+			if minLen < 0 || n < minLen {
+				minLen = n
+			}
+			if n > maxLen {
+				maxLen = n
+			}
+		}
+
+		if kind == segOther || (same && (level != levelLoose || kind == segSpace)) {
+			builder.WriteString(regexp.QuoteMeta(column[0].text))
+			continue
+		}
+
+		var class string
+		switch kind {
+		case segDigits:
+			class = `\d`
+		case segWord:
+			class = `\w`
+		case segSpace:
+			class = `\s`
+		case segLetters:
+			class = `\w`
+			if level != levelLoose {
+				class = letterClass(column)
+			}
+		}
+		builder.WriteString(class)
+		if level == levelPrecise && kind != segSpace {
+			builder.WriteString(repeatSuffix(minLen, maxLen))
+		} else {
+			builder.WriteString("+")
+		}
+	}
+	return builder.String()
+}
+
+// letterClass returns the narrowest class covering the letters of the column.
+func letterClass(column []segment) string {
+	lower, upper, other := false, false, false
+	for _, seg := range column {
+		for _, r := range seg.text {
+			switch {
+			case r >= 'a' && r <= 'z':
+				lower = true
+			case r >= 'A' && r <= 'Z':
+				upper = true
+			default:
+				other = true
+			}
+		}
+	}
+	switch {
+	case other:
+		return `\pL`
+	case lower && upper:
+		return `[A-Za-z]`
+	case upper:
+		return `[A-Z]`
+	}
+	return `[a-z]`
+}
+
+// repeatSuffix returns the shortest repetition operator for the given length range.
+func repeatSuffix(minLen, maxLen int) string {
+	switch {
+	case minLen == 1 && maxLen == 1:
+		return ""
+	case minLen == maxLen:
+		return fmt.Sprintf("{%d}", minLen)
+	}
+	return fmt.Sprintf("{%d,%d}", minLen, maxLen)
+}
+
+// delimitedPattern keeps the common prefix and suffix of the examples and accepts anything
+// up to the suffix's first character in between, e.g. `id=[^;]+;`.
+func delimitedPattern(examples []string) string {
+	if len(examples) < 2 {
+		return ""
+	}
+	prefix, suffix := examples[0], examples[0]
+	for _, example := range examples[1:] {
+		prefix = commonPrefix(prefix, example)
+		suffix = commonSuffix(suffix, example)
+	}
+	if prefix == "" && suffix == "" {
+		return ""
+	}
+
+	shortest := len(examples[0])
+	for _, example := range examples {
+		shortest = min(shortest, len(example))
+	}
+	// Prefix and suffix must not overlap in the shortest example.
+	for len(prefix)+len(suffix) > shortest {
+		_, size := utf8.DecodeLastRuneInString(suffix)
+		suffix = suffix[:len(suffix)-size]
+	}
+
+	middle := `\S`
+	if suffix != "" {
+		r, _ := utf8.DecodeRuneInString(suffix)
+		middle = "[^" + regexp.QuoteMeta(string(r)) + "]"
+	}
+	repeat := "+"
+	for _, example := range examples {
+		if len(example) == len(prefix)+len(suffix) {
+			repeat = "*"
+		}
+	}
+	return regexp.QuoteMeta(prefix) + middle + repeat + regexp.QuoteMeta(suffix)
+}
+
+func commonPrefix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	for n > 0 && n < len(a) && !utf8.RuneStart(a[n]) {
+		n--
+	}
+	return a[:n]
+}
+
+func commonSuffix(a, b string) string {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	s := a[len(a)-n:]
+	for s != "" && !utf8.RuneStart(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
+
+// alternationPattern lists the distinct examples literally, longest first so that
+// leftmost-first matching prefers the longest alternative.
+func alternationPattern(examples []string) string {
+	var distinct []string
+	seen := map[string]bool{}
+	for _, example := range examples {
+		if !seen[example] {
+			seen[example] = true
+			distinct = append(distinct, regexp.QuoteMeta(example))
+		}
+	}
+	if len(distinct) == 1 {
+		return distinct[0]
+	}
+	sort.SliceStable(distinct, func(i, j int) bool { return len(distinct[i]) > len(distinct[j]) })
+	return "(?:" + strings.Join(distinct, "|") + ")"
+}
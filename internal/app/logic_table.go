@@ -0,0 +1,96 @@
+package app
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResultTable holds the matches as a table: a row per match and a column per group, the
+// whole match first.
+type ResultTable struct {
+	Headers []string // Group names, or numbers for unnamed groups.
+	Rows    [][]string
+}
+
+// TableOptions selects and orders the rows of a result table.
+type TableOptions struct {
+	SortColumn int // Column to sort by, -1 to keep the order of the matches.
+	Descending bool
+	Filters    map[int]*regexp.Regexp // Pattern the cells of a column must match, by column.
+}
+
+// NewResultTable returns the table of the matches of the pattern.
+func NewResultTable(regexStr string, matches [][]string) (ResultTable, error) {
+	re, err := regexp.Compile(regexStr)
+	if err != nil {
+		return ResultTable{}, err
+	}
+	return ResultTable{Headers: groupHeaders(re), Rows: matches}, nil
+}
+
+// Visible returns the rows that pass the column filters, sorted by the sort column.
+// Filters and sorting on columns the table doesn't have are ignored.
+func (t ResultTable) Visible(opts TableOptions) [][]string {
+	var rows [][]string
+	for _, row := range t.Rows {
+		passes := true
+		for column, re := range opts.Filters {
+			if column < len(row) && !re.MatchString(row[column]) {
+				passes = false
+				break
+			}
+		}
+		if passes {
+			rows = append(rows, row)
+		}
+	}
+
+	if column := opts.SortColumn; column >= 0 && column < len(t.Headers) {
+		sort.SliceStable(rows, func(i, j int) bool {
+			if opts.Descending {
+				return compareCells(rows[j][column], rows[i][column]) < 0
+			}
+			return compareCells(rows[i][column], rows[j][column]) < 0
+		})
+	}
+	return rows
+}
+
+// IsNumber reports whether a cell holds a number. Numbers are right-aligned and sorted by value.
+func IsNumber(cell string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+	return err == nil
+}
+
+// compareCells compares numbers by value, before the other cells, which are compared as strings.
+func compareCells(a, b string) int {
+	x, errX := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	switch {
+	case errX == nil && errY == nil:
+		return cmp.Compare(x, y)
+	case errX == nil:
+		return -1
+	case errY == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// GenerateTableExportCSV generates CSV with a header row followed by the rows.
+func GenerateTableExportCSV(headers []string, rows [][]string) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write(headers); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// TemplateMatch is a match as passed to the template of GenerateExportTemplate.
+type TemplateMatch struct {
+	Index  int               // 0-based index of the match.
+	Groups []string          // The whole match, then each group.
+	Named  map[string]string // The named groups, by name.
+	Line   int               // 1-based line of the start of the match.
+	Col    int               // 1-based column of the start of the match, in characters.
+}
+
+// TemplateData is passed to the header and footer templates.
+type TemplateData struct {
+	Regex   string
+	Matches []TemplateMatch
+}
+
+// Names of the templates, defined with {{define "header"}}...{{end}}, rendered before and
+// after the matches.
+const (
+	TemplateHeader = "header"
+	TemplateFooter = "footer"
+)
+
+// templateFuncs are the helpers of the templates, named like their Sprig counterparts.
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"replace": func(old, new, s string) string {
+		return strings.ReplaceAll(s, old, new)
+	},
+	"json": func(v any) (string, error) {
+		var buffer bytes.Buffer
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetEscapeHTML(false)
+		err := encoder.Encode(v)
+		return strings.TrimSuffix(buffer.String(), "\n"), err
+	},
+	"default": func(def, v any) any {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+}
+
+// GenerateExportTemplate renders a Go text/template for each match of the text, given by
+// its byte offsets and groups, after the "header" template and before the "footer" one if
+// they are defined. Each rendering that isn't empty ends with a newline; matches rendered
+// as nothing, e.g. by {{if}}, are left out. Unknown names in .Named are errors.
+func GenerateExportTemplate(regexStr, text string, indices [][]int, matches [][]string, tmpl string) ([]byte, error) {
+	if tmpl == "" {
+		return nil, fmt.Errorf("the template cannot be empty")
+	}
+	re, err := regexp.Compile(regexStr)
+	if err != nil {
+		return nil, err
+	}
+	t, err := template.New("match").Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	names := re.SubexpNames()
+	data := TemplateData{Regex: regexStr, Matches: make([]TemplateMatch, len(matches))}
+	counter := lineCounter{text: text}
+	for i, match := range matches {
+		m := TemplateMatch{Index: i, Groups: match, Named: make(map[string]string)}
+		for g, name := range names {
+			if name != "" && g < len(match) {
+				m.Named[name] = match[g]
+			}
+		}
+		m.Line, m.Col = counter.position(indices[i][0])
+		data.Matches[i] = m
+	}
+
+	var buffer bytes.Buffer
+	render := func(t *template.Template, data any) error {
+		var section strings.Builder
+		if err := t.Execute(&section, data); err != nil {
+			return err
+		}
+		if s := section.String(); s != "" {
+			buffer.WriteString(s)
+			if !strings.HasSuffix(s, "\n") {
+				buffer.WriteString("\n")
+			}
+		}
+		return nil
+	}
+	if header := t.Lookup(TemplateHeader); header != nil {
+		if err := render(header, data); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range data.Matches {
+		if err := render(t, m); err != nil {
+			return nil, err
+		}
+	}
+	if footer := t.Lookup(TemplateFooter); footer != nil {
+		if err := render(footer, data); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
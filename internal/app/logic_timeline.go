@@ -0,0 +1,197 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LayoutUnix is the layout of Unix timestamps, in seconds or, with 13 digits or more, milliseconds.
+const LayoutUnix = "unix"
+
+// TimestampLayouts are the layouts tried, in order, to detect the layout of timestamps.
+var TimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006/01/02 15:04:05.999999999",
+	"02/Jan/2006:15:04:05 -0700", // Common Log Format
+	time.RFC1123Z,
+	time.RFC1123,
+	time.UnixDate,
+	time.ANSIC,
+	time.Stamp, // Syslog
+	"15:04:05",
+	LayoutUnix,
+}
+
+// BucketSizes are the durations a timeline can be bucketed by, named by BucketNames.
+var (
+	BucketSizes = []time.Duration{time.Second, time.Minute, time.Hour}
+	BucketNames = []string{"second", "minute", "hour"}
+)
+
+// maxBuckets limits the number of buckets between the first and the last timestamp.
+const maxBuckets = 5000
+
+// TimelineOptions configures the timeline of the matches.
+type TimelineOptions struct {
+	Group  int           // Group holding the timestamp, 0 for the whole match.
+	Layout string        // Go time layout or LayoutUnix, empty to detect it.
+	Bucket time.Duration // One of BucketSizes.
+}
+
+// TimeBucket counts the matches whose timestamp is in [Start, Start+size).
+type TimeBucket struct {
+	Start time.Time
+	Count int
+}
+
+// Timeline holds the timestamp of each match and the buckets from the first to the last one.
+type Timeline struct {
+	Layout  string
+	Times   []time.Time // Timestamp of each match, zero if it didn't parse.
+	Skipped int         // Matches whose timestamp didn't parse.
+	Buckets []TimeBucket
+}
+
+// parseTimestamp parses a timestamp with a layout of TimestampLayouts or any Go layout.
+func parseTimestamp(value, layout string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if layout != LayoutUnix {
+		return time.Parse(layout, value)
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(strings.TrimPrefix(value, "-")) >= 13 {
+		return time.UnixMilli(n).UTC(), nil
+	}
+	return time.Unix(n, 0).UTC(), nil
+}
+
+// DetectLayout returns the first layout of TimestampLayouts that parses the most values.
+func DetectLayout(values []string) (string, error) {
+	if len(values) > 100 {
+		values = values[:100]
+	}
+	best, bestCount := "", 0
+	for _, layout := range TimestampLayouts {
+		count := 0
+		for _, value := range values {
+			if _, err := parseTimestamp(value, layout); err == nil {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = layout, count
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no known timestamp layout matches, e.g. %q", values[0])
+	}
+	return best, nil
+}
+
+// BuildTimeline parses the timestamps of the matches and counts them per bucket. Empty
+// buckets between the first and the last timestamp are included.
+func BuildTimeline(matches [][]string, opts TimelineOptions) (Timeline, error) {
+	var values []string
+	for _, match := range matches {
+		if opts.Group < len(match) && match[opts.Group] != "" {
+			values = append(values, match[opts.Group])
+		}
+	}
+	timeline := Timeline{Layout: opts.Layout, Times: make([]time.Time, len(matches))}
+	if len(values) == 0 {
+		return timeline, nil
+	}
+	if timeline.Layout == "" {
+		var err error
+		if timeline.Layout, err = DetectLayout(values); err != nil {
+			return timeline, err
+		}
+	}
+
+	var first, last time.Time
+	for i, match := range matches {
+		var t time.Time
+		var err error
+		if opts.Group < len(match) {
+			t, err = parseTimestamp(match[opts.Group], timeline.Layout)
+		}
+		if opts.Group >= len(match) || err != nil {
+			timeline.Skipped++
+			continue
+		}
+		timeline.Times[i] = t
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if last.IsZero() || t.After(last) {
+			last = t
+		}
+	}
+	if first.IsZero() {
+		return timeline, fmt.Errorf("no timestamp matches the layout %q", timeline.Layout)
+	}
+
+	first, last = first.Truncate(opts.Bucket), last.Truncate(opts.Bucket)
+	count := int(last.Sub(first)/opts.Bucket) + 1
+	if count > maxBuckets {
+		return timeline, fmt.Errorf("%d buckets of %v are too many, choose a larger bucket", count, opts.Bucket)
+	}
+	timeline.Buckets = make([]TimeBucket, count)
+	for i := range timeline.Buckets {
+		timeline.Buckets[i].Start = first.Add(time.Duration(i) * opts.Bucket)
+	}
+	for _, t := range timeline.Times {
+		if !t.IsZero() {
+			timeline.Buckets[t.Truncate(opts.Bucket).Sub(first)/opts.Bucket].Count++
+		}
+	}
+	return timeline, nil
+}
+
+// InBucket reports whether the timestamp of a match is in the bucket starting at start.
+func (t Timeline) InBucket(match int, start time.Time, size time.Duration) bool {
+	ts := t.Times[match]
+	return !ts.IsZero() && !ts.Before(start) && ts.Before(start.Add(size))
+}
+
+// FormatBucket formats the start of a bucket down to its size, without the date if the
+// timestamps have none.
+func FormatBucket(start time.Time, size time.Duration) string {
+	date := "2006-01-02 "
+	if start.Year() == 0 {
+		date = ""
+	}
+	switch size {
+	case time.Second:
+		return start.Format(date + "15:04:05")
+	case time.Minute:
+		return start.Format(date + "15:04")
+	}
+	return start.Format(date + "15h")
+}
+
+// GenerateTimelineExportCSV generates CSV with the start, as RFC 3339, and the count of each bucket.
+func GenerateTimelineExportCSV(buckets []TimeBucket) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write([]string{"start", "count"}); err != nil {
+		return nil, err
+	}
+	for _, bucket := range buckets {
+		if err := writer.Write([]string{bucket.Start.Format(time.RFC3339), strconv.Itoa(bucket.Count)}); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
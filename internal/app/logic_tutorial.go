@@ -0,0 +1,251 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Exercise is a step of the tutorial. Its text marks the spans the pattern must match
+// with [[...]] and the spans it must not touch with {{...}}.
+type Exercise struct {
+	ID    string
+	Title string
+	Goal  string
+	Text  string
+	Hints []string
+}
+
+// Exercises is the built-in tutorial, from the basics to Unicode classes.
+var Exercises = []Exercise{
+	{
+		ID: "literal", Title: "Literal text",
+		Goal: "Match the word cat wherever it appears.",
+		Text: "The [[cat]] sat on the mat.\nA [[cat]] is not a dog.",
+		Hints: []string{
+			"Most characters match themselves.",
+			"Try cat",
+		},
+	},
+	{
+		ID: "dot", Title: "The dot and escaping",
+		Goal: "Match the file names ending in .go, but not the ones where the dot is another character.",
+		Text: "[[main.go]] {{mainxgo}} [[util.go]] {{util-go}}",
+		Hints: []string{
+			"A dot matches any character; to match a literal dot, escape it with a backslash.",
+			`\w+ matches a run of word characters.`,
+			`Try \w+\.go`,
+		},
+	},
+	{
+		ID: "class", Title: "Character classes",
+		Goal: "Match gray and grey, but not groy.",
+		Text: "[[gray]] [[grey]] {{groy}}",
+		Hints: []string{
+			"A class in brackets matches one character out of a set.",
+			"Try gr[ae]y",
+		},
+	},
+	{
+		ID: "digits", Title: "Digits and repetition",
+		Goal: "Match the whole numbers.",
+		Text: "Order [[66]] of [[1977]], page [[7]].",
+		Hints: []string{
+			`\d matches one digit.`,
+			"+ repeats the previous item one or more times.",
+			`Try \d+`,
+		},
+	},
+	{
+		ID: "counted", Title: "Counted repetition",
+		Goal: "Match the four-digit years only.",
+		Text: "In [[1999]] and [[2024]], but not {{12}} or {{123456}}.",
+		Hints: []string{
+			"{n} repeats exactly n times.",
+			`A longer number contains four digits too: \b marks a word boundary.`,
+			`Try \b\d{4}\b`,
+		},
+	},
+	{
+		ID: "anchors", Title: "Anchors and flags",
+		Goal: "Match ERROR only at the start of a line.",
+		Text: "[[ERROR]] disk full\nINFO retrying after {{ERROR}}\n[[ERROR]] giving up",
+		Hints: []string{
+			"^ matches at the start of the text.",
+			"The m flag makes ^ match at the start of every line.",
+			"Try (?m)^ERROR",
+		},
+	},
+	{
+		ID: "alternation", Title: "Alternation",
+		Goal: "Match the log levels WARN and ERROR, not INFO.",
+		Text: "[[WARN]] low disk\n{{INFO}} started\n[[ERROR]] crashed",
+		Hints: []string{
+			"| matches either the left or the right side.",
+			"Try WARN|ERROR",
+		},
+	},
+	{
+		ID: "groups", Title: "Groups",
+		Goal: "Match the repeated ha, as a whole.",
+		Text: "[[hahaha]] and [[haha]], but not a single {{h}}.",
+		Hints: []string{
+			"Parentheses group several items so that a repetition applies to all of them.",
+			"Try (ha)+ or (?:ha)+",
+		},
+	},
+	{
+		ID: "lazy", Title: "Lazy repetition",
+		Goal: "Match each HTML tag on its own.",
+		Text: "[[<b>]]bold[[</b>]] and [[<i>]]italic[[</i>]]",
+		Hints: []string{
+			"<.*> matches from the first < to the last >, because * prefers more.",
+			"*? prefers fewer repetitions.",
+			"Try <.*?>",
+		},
+	},
+	{
+		ID: "negated", Title: "Negated classes",
+		Goal: "Match the quoted values including their quotes.",
+		Text: `name=[["alice"]] role=[["admin"]] note=[[""]]`,
+		Hints: []string{
+			"[^...] matches any character except the listed ones.",
+			`Try "[^"]*"`,
+		},
+	},
+	{
+		ID: "unicode", Title: "Unicode classes",
+		Goal: "Match the runs of Han characters.",
+		Text: "Tokyo is [[東京]] and Kyoto is [[京都]]; {{とうきょう}} is Hiragana.",
+		Hints: []string{
+			`\p{Name} matches a character of a Unicode category or script.`,
+			`Try \p{Han}+`,
+		},
+	},
+}
+
+// ParseExercise returns the plain text of an exercise with the spans to match and
+// the spans not to match.
+func ParseExercise(markup string) (string, []Span, []Span) {
+	var text strings.Builder
+	var match, noMatch []Span
+	for len(markup) > 0 {
+		open := strings.IndexAny(markup, "[{")
+		if open < 0 {
+			text.WriteString(markup)
+			break
+		}
+		delim := markup[open : open+1]
+		closing := map[string]string{"[": "]]", "{": "}}"}[delim]
+		end := strings.Index(markup[open+2:], closing)
+		if !strings.HasPrefix(markup[open:], delim+delim) || end < 0 {
+			text.WriteString(markup[:open+1])
+			markup = markup[open+1:]
+			continue
+		}
+		text.WriteString(markup[:open])
+		span := Span{Start: text.Len(), End: text.Len() + end}
+		text.WriteString(markup[open+2 : open+2+end])
+		if delim == "[" {
+			match = append(match, span)
+		} else {
+			noMatch = append(noMatch, span)
+		}
+		markup = markup[open+2+end+2:]
+	}
+	return text.String(), match, noMatch
+}
+
+// SpanCheck is the state of one marked span of an exercise.
+type SpanCheck struct {
+	Text   string
+	Match  bool // Whether the span must be matched, or must not be.
+	Passed bool
+}
+
+// CheckExercise checks a pattern against the marked spans of an exercise. The exercise is
+// solved if every span is passed.
+func CheckExercise(exercise Exercise, pattern string) ([]SpanCheck, bool, error) {
+	text, match, noMatch := ParseExercise(exercise.Text)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, false, err
+	}
+	var matches [][]int
+	if pattern != "" {
+		matches = re.FindAllStringIndex(text, -1)
+	}
+
+	solved := true
+	var checks []SpanCheck
+	for _, span := range match {
+		passed := coversSpans(matches, []Span{span})
+		checks = append(checks, SpanCheck{Text: text[span.Start:span.End], Match: true, Passed: passed})
+		solved = solved && passed
+	}
+	for _, span := range noMatch {
+		passed := !overlapsSpans(matches, []Span{span})
+		checks = append(checks, SpanCheck{Text: text[span.Start:span.End], Passed: passed})
+		solved = solved && passed
+	}
+	return checks, solved, nil
+}
+
+// TutorialProgress records the solved exercises.
+type TutorialProgress struct {
+	Solved map[string]bool `json:"solved"`
+}
+
+// TutorialProgressPath returns the progress file next to the history file, or "" if
+// there is no history file, in which case progress is not persisted.
+func TutorialProgressPath(historyPath string) string {
+	if historyPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(historyPath), "tutorial.json")
+}
+
+// LoadTutorialProgress reads the progress file. A missing file means no progress.
+func LoadTutorialProgress(path string) (TutorialProgress, error) {
+	progress := TutorialProgress{Solved: map[string]bool{}}
+	if path == "" {
+		return progress, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return progress, nil
+	} else if err != nil {
+		return progress, err
+	}
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return TutorialProgress{Solved: map[string]bool{}}, fmt.Errorf("%s: %w", path, err)
+	}
+	if progress.Solved == nil {
+		progress.Solved = map[string]bool{}
+	}
+	return progress, nil
+}
+
+// SaveTutorialProgress writes the progress file. If the path is empty, it does nothing.
+func SaveTutorialProgress(path string, progress TutorialProgress) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Unlocked reports whether the exercise at index is available: the first one always is,
+// the others once the previous one is solved.
+func (p TutorialProgress) Unlocked(index int) bool {
+	return index == 0 || (index < len(Exercises) && p.Solved[Exercises[index-1].ID])
+}
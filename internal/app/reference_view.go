@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ReferenceView is the searchable RE2 syntax reference. The selected entry's example is
+// shown below the table and can be tried in the main window.
+type ReferenceView struct {
+	*tview.Box
+	table        *tview.Table
+	searchField  *tview.InputField
+	example      *tview.TextView
+	flex         *tview.Flex
+	requestFocus func(p tview.Primitive) // Callback to request application focus
+	entries      []SyntaxEntry           // All entries
+	filtered     []SyntaxEntry           // Entries matching the filter
+	onTry        func(entry SyntaxEntry)
+	onClose      func()
+}
+
+// NewReferenceView creates a new ReferenceView with the complete syntax reference.
+func NewReferenceView(requestFocus func(p tview.Primitive)) *ReferenceView {
+	rv := &ReferenceView{
+		Box:          tview.NewBox(),
+		table:        tview.NewTable().SetSelectable(true, false).SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkCyan)),
+		searchField:  tview.NewInputField().SetLabel("Search: "),
+		example:      tview.NewTextView(),
+		requestFocus: requestFocus,
+		entries:      SyntaxReference(),
+	}
+
+	rv.table.SetBorder(true).SetTitle(TitleReference)
+	rv.searchField.SetBorder(true)
+	rv.example.SetBorder(true).SetTitle(TitleReferenceExample)
+	rv.example.SetDynamicColors(true)
+
+	rv.flex = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(rv.searchField, 3, 1, true).
+		AddItem(rv.table, 0, 1, false).
+		AddItem(rv.example, 7, 0, false)
+
+	rv.searchField.SetChangedFunc(rv.filterEntries)
+	rv.searchField.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter, tcell.KeyDown:
+			rv.requestFocus(rv.table)
+			return nil
+		}
+		return event
+	})
+
+	rv.table.SetSelectionChangedFunc(func(row, column int) {
+		rv.updateExample()
+	})
+	rv.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			rv.tryEntry()
+			return nil
+		case tcell.KeyUp:
+			if row, _ := rv.table.GetSelection(); row <= 1 {
+				rv.requestFocus(rv.searchField)
+				return nil
+			}
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 't':
+				rv.tryEntry()
+				return nil
+			case 'j':
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			case 'k':
+				if row, _ := rv.table.GetSelection(); row <= 1 {
+					rv.requestFocus(rv.searchField)
+					return nil
+				}
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			case 'q':
+				if rv.onClose != nil {
+					rv.onClose()
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	rv.filterEntries("")
+	return rv
+}
+
+// SetOnTry sets the callback for when the user wants to try an entry's example.
+func (rv *ReferenceView) SetOnTry(handler func(entry SyntaxEntry)) {
+	rv.onTry = handler
+}
+
+// SetOnClose sets the callback for when the view is closed.
+func (rv *ReferenceView) SetOnClose(handler func()) {
+	rv.onClose = handler
+}
+
+// current returns the selected entry, or false if there is none.
+func (rv *ReferenceView) current() (SyntaxEntry, bool) {
+	row, _ := rv.table.GetSelection()
+	if row < 1 || row > len(rv.filtered) {
+		return SyntaxEntry{}, false
+	}
+	return rv.filtered[row-1], true
+}
+
+func (rv *ReferenceView) tryEntry() {
+	if entry, ok := rv.current(); ok && entry.Example != "" && rv.onTry != nil {
+		rv.onTry(entry)
+	}
+}
+
+// filterEntries shows the entries whose category, syntax or description contain the search text.
+func (rv *ReferenceView) filterEntries(searchText string) {
+	rv.table.Clear()
+	rv.filtered = nil
+
+	headers := []string{"Category", "Syntax", "Description"}
+	for i, header := range headers {
+		rv.table.SetCell(0, i, tview.NewTableCell(header).SetSelectable(false).SetAlign(tview.AlignCenter).SetBackgroundColor(tcell.ColorDarkBlue))
+	}
+
+	searchText = strings.ToLower(searchText)
+	rowIndex := 1
+	for _, entry := range rv.entries {
+		haystack := strings.ToLower(entry.Category + "\n" + entry.Syntax + "\n" + entry.Description)
+		if searchText != "" && !strings.Contains(haystack, searchText) {
+			continue
+		}
+		rv.filtered = append(rv.filtered, entry)
+		rv.table.SetCell(rowIndex, 0, tview.NewTableCell(entry.Category).SetExpansion(1))
+		rv.table.SetCell(rowIndex, 1, tview.NewTableCell(tview.Escape(entry.Syntax)).SetExpansion(1).SetTextColor(tcell.ColorGreen))
+		rv.table.SetCell(rowIndex, 2, tview.NewTableCell(tview.Escape(entry.Description)).SetExpansion(4))
+		rowIndex++
+	}
+	if len(rv.filtered) == 0 {
+		rv.table.SetCell(1, 2, tview.NewTableCell("No syntax found.").SetSelectable(false))
+		rv.table.SetOffset(0, 0)
+	}
+	rv.table.Select(1, 0)
+	rv.updateExample()
+}
+
+// updateExample shows the example of the selected entry with its matches highlighted.
+func (rv *ReferenceView) updateExample() {
+	entry, ok := rv.current()
+	if !ok {
+		rv.example.SetText("")
+		return
+	}
+	if entry.Example == "" {
+		rv.example.SetText("[yellow]No example: " + tview.Escape(entry.Description) + "[-]")
+		return
+	}
+	re, err := regexp.Compile(entry.Example)
+	if err != nil {
+		rv.example.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+		return
+	}
+	rv.example.SetText(fmt.Sprintf("[yellow]Pattern:[-] %s\n[yellow]Text:[-]\n%s",
+		tview.Escape(entry.Example), highlightMatches(entry.Text, re.FindAllStringIndex(entry.Text, -1))))
+}
+
+// Draw implements tview.Primitive.
+func (rv *ReferenceView) Draw(screen tcell.Screen) {
+	rv.flex.Draw(screen)
+}
+
+// GetRect implements tview.Primitive.
+func (rv *ReferenceView) GetRect() (int, int, int, int) {
+	return rv.flex.GetRect()
+}
+
+// SetRect implements tview.Primitive.
+func (rv *ReferenceView) SetRect(x, y, width, height int) {
+	rv.flex.SetRect(x, y, width, height)
+}
+
+// InputHandler returns the handler for this primitive.
+func (rv *ReferenceView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return rv.flex.InputHandler()
+}
+
+// Focus is called when this primitive receives focus.
+func (rv *ReferenceView) Focus(delegate func(p tview.Primitive)) {
+	delegate(rv.searchField)
+}
+
+// HasFocus returns whether this primitive has focus.
+func (rv *ReferenceView) HasFocus() bool {
+	return rv.searchField.HasFocus() || rv.table.HasFocus()
+}
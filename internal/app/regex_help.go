@@ -1,15 +1,58 @@
 package app
 
 // HelpItem represents a single entry in the help document.
+// Entries of user-defined libraries may also carry the optional fields.
 type HelpItem struct {
-	Title   string
-	Pattern string
+	Title       string   `json:"title" yaml:"title"`
+	Pattern     string   `json:"pattern" yaml:"pattern"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Flags       string   `json:"flags,omitempty" yaml:"flags,omitempty"` // RE2 flags such as "i" or "sm".
+	Sample      string   `json:"sample,omitempty" yaml:"sample,omitempty"`
+}
+
+// FullPattern returns the pattern with its flags applied.
+func (h HelpItem) FullPattern() string {
+	if h.Flags == "" {
+		return h.Pattern
+	}
+	return "(?" + h.Flags + ")" + h.Pattern
+}
+
+// HelpSection is a group of help items loaded from one library file.
+type HelpSection struct {
+	Source string
+	Items  []HelpItem
 }
 
 // HelpContent contains the different sections of the help document.
 type HelpContent struct {
 	Common  []HelpItem
 	Escapes []HelpItem
+	Custom  []HelpSection // User-defined libraries, one section per file.
+}
+
+// LibraryEntry is a help item together with the section it belongs to.
+type LibraryEntry struct {
+	Section string
+	HelpItem
+}
+
+// Entries returns all help items in display order.
+func (h HelpContent) Entries() []LibraryEntry {
+	var entries []LibraryEntry
+	for _, item := range h.Common {
+		entries = append(entries, LibraryEntry{Section: "Common", HelpItem: item})
+	}
+	for _, item := range h.Escapes {
+		entries = append(entries, LibraryEntry{Section: "Escapes", HelpItem: item})
+	}
+	for _, section := range h.Custom {
+		for _, item := range section.Items {
+			entries = append(entries, LibraryEntry{Section: section.Source, HelpItem: item})
+		}
+	}
+	return entries
 }
 
 // RegexHelpData holds all the predefined help information.
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SyntaxEntry is an element of the RE2 syntax reference, with a small example to try.
+type SyntaxEntry struct {
+	Category    string
+	Syntax      string
+	Description string
+	Example     string // Pattern to try, empty for unsupported syntax.
+	Text        string // Text the example is tried on.
+}
+
+// Syntax reference categories
+const (
+	SynSingle      = "Characters"
+	SynClass       = "Class"
+	SynPOSIX       = "POSIX class"
+	SynPerl        = "Perl class"
+	SynUnicodeCat  = "Unicode category"
+	SynUnicodeScr  = "Unicode script"
+	SynComposite   = "Composite"
+	SynRepeat      = "Repetition"
+	SynGroup       = "Group"
+	SynFlag        = "Flag"
+	SynAnchor      = "Anchor"
+	SynEscape      = "Escape"
+	SynUnsupported = "Not in RE2"
+)
+
+var syntaxBase = []SyntaxEntry{
+	{SynSingle, `.`, "Any character, possibly newline with flag s", `a.c`, "abc a-c ac a\nc"},
+	{SynSingle, `x`, "A literal character", `x`, "box fox"},
+
+	{SynClass, `[xyz]`, "Any of the characters", `[aeiou]`, "regular expression"},
+	{SynClass, `[^xyz]`, "Any character except these", `[^aeiou ]`, "regular expression"},
+	{SynClass, `[a-z]`, "A range of characters", `[a-f0-9]+`, "color #1e90ff, id deadbeef"},
+	{SynClass, `[\d]`, "Perl class inside a class", `[\d.]+`, "pi is 3.14, e is 2.718"},
+	{SynClass, `[[:alpha:]]`, "ASCII class inside a class", `[[:alpha:]_]+`, "snake_case and 42"},
+	{SynClass, `[\p{Greek}]`, "Unicode class inside a class", `[\p{Greek}\d]+`, "αβγ123 abc"},
+
+	{SynPerl, `\d`, "Digit, [0-9]", `\d+`, "order 66 of 1977"},
+	{SynPerl, `\D`, "Not a digit", `\D+`, "order 66 of 1977"},
+	{SynPerl, `\s`, "Whitespace, [\\t\\n\\f\\r ]", `\s+`, "tab\there  two  spaces"},
+	{SynPerl, `\S`, "Not whitespace", `\S+`, "tab\there  two  spaces"},
+	{SynPerl, `\w`, "Word character, [0-9A-Za-z_]", `\w+`, "snake_case, kebab-case, café"},
+	{SynPerl, `\W`, "Not a word character", `\W+`, "snake_case, kebab-case, café"},
+
+	{SynComposite, `xy`, "x followed by y", `ab`, "abc acb"},
+	{SynComposite, `x|y`, "x or y, the leftmost alternative that matches wins", `cat|category`, "category"},
+
+	{SynRepeat, `x*`, "Zero or more x, prefer more", `ab*`, "a ab abbb"},
+	{SynRepeat, `x+`, "One or more x, prefer more", `ab+`, "a ab abbb"},
+	{SynRepeat, `x?`, "Zero or one x, prefer one", `colou?r`, "color colour"},
+	{SynRepeat, `x{n,m}`, "n to m x, prefer more", `\d{2,3}`, "1 12 123 1234"},
+	{SynRepeat, `x{n,}`, "n or more x, prefer more", `\d{2,}`, "1 12 123 1234"},
+	{SynRepeat, `x{n}`, "Exactly n x", `\d{2}`, "1 12 123 1234"},
+	{SynRepeat, `x*?`, "Zero or more x, prefer fewer", `<.*?>`, "<b>bold</b>"},
+	{SynRepeat, `x+?`, "One or more x, prefer fewer", `a+?`, "aaa"},
+	{SynRepeat, `x??`, "Zero or one x, prefer zero", `ab??`, "ab"},
+	{SynRepeat, `x{n,m}?`, "n to m x, prefer fewer", `\d{2,3}?`, "12345"},
+
+	{SynGroup, `(re)`, "Numbered capturing group", `(\w+)@(\w+)`, "mail alice@example"},
+	{SynGroup, `(?P<name>re)`, "Named and numbered capturing group", `(?P<user>\w+)@(?P<host>\w+)`, "mail alice@example"},
+	{SynGroup, `(?<name>re)`, "Named capturing group, alternative syntax (Go 1.22+)", `(?<user>\w+)@`, "mail alice@example"},
+	{SynGroup, `(?:re)`, "Non-capturing group", `(?:ab)+`, "ababab"},
+	{SynGroup, `(?flags)`, "Set flags for the rest of the current group", `(?i)hello`, "Hello HELLO hello"},
+	{SynGroup, `(?flags:re)`, "Set flags during re only", `(?i:h)ello`, "Hello HELLO hello"},
+
+	{SynFlag, `i`, "Case-insensitive", `(?i)go`, "Go GO go"},
+	{SynFlag, `m`, "Multi-line: ^ and $ match at line boundaries", `(?m)^\w+`, "first line\nsecond line"},
+	{SynFlag, `s`, "Let . match \\n", `(?s)a.b`, "a\nb"},
+	{SynFlag, `U`, "Ungreedy: swap the meaning of x* and x*?, x+ and x+?, etc.", `(?U)<.*>`, "<b>bold</b>"},
+
+	{SynAnchor, `^`, "Beginning of text, or of line with flag m", `^\w+`, "first line\nsecond line"},
+	{SynAnchor, `$`, "End of text (not before a final \\n), or of line with flag m", `\w+$`, "first line\nsecond line"},
+	{SynAnchor, `\A`, "Beginning of text, even with flag m", `(?m)\A\w+`, "first line\nsecond line"},
+	{SynAnchor, `\z`, "End of text, even with flag m", `(?m)\w+\z`, "first line\nsecond line"},
+	{SynAnchor, `\b`, "ASCII word boundary", `\bcat\b`, "cat concat cats cat."},
+	{SynAnchor, `\B`, "Not an ASCII word boundary", `\Bcat`, "cat concat cats cat."},
+
+	{SynEscape, `\*`, "A literal punctuation character", `\$\d+\.\d{2}`, "costs $12.50"},
+	{SynEscape, `\Q...\E`, "Literal text, even if it has punctuation", `\Q1+1=2\E`, "1+1=2 11=2"},
+	{SynEscape, `\t \n \r`, "Tab, newline, carriage return", `\t`, "a\tb"},
+	{SynEscape, `\a \f \v`, "Bell, form feed, vertical tab", `\f`, "page\fbreak"},
+	{SynEscape, `\123`, "Octal character code (up to three digits)", `\101`, "ABC"},
+	{SynEscape, `\x7F`, "Hex character code (exactly two digits)", `\x41`, "ABC"},
+	{SynEscape, `\x{10FFFF}`, "Hex character code", `\x{263A}`, "smile ☺"},
+
+	{SynUnsupported, `(?=re) (?!re)`, "Lookahead is not supported; match more and use groups instead", "", ""},
+	{SynUnsupported, `(?<=re) (?<!re)`, "Lookbehind is not supported; match the prefix in a non-capturing group", "", ""},
+	{SynUnsupported, `\1`, "Backreferences are not supported", "", ""},
+	{SynUnsupported, `(?>re) x*+`, "Atomic groups and possessive repetitions are not supported", "", ""},
+	{SynUnsupported, `\Z \G \K \R \h`, "These PCRE escapes are not supported", "", ""},
+}
+
+// posixClasses are the ASCII classes accepted in [[:name:]], with their meaning.
+var posixClasses = [][2]string{
+	{"alnum", "Alphanumeric, [0-9A-Za-z]"},
+	{"alpha", "Alphabetic, [A-Za-z]"},
+	{"ascii", "ASCII, [\\x00-\\x7F]"},
+	{"blank", "Blank, [\\t ]"},
+	{"cntrl", "Control, [\\x00-\\x1F\\x7F]"},
+	{"digit", "Digits, [0-9]"},
+	{"graph", "Graphical, [!-~]"},
+	{"lower", "Lower case, [a-z]"},
+	{"print", "Printable, [ -~]"},
+	{"punct", "Punctuation, [!-/:-@[-`{-~]"},
+	{"space", "Whitespace, [\\t\\n\\v\\f\\r ]"},
+	{"upper", "Upper case, [A-Z]"},
+	{"word", "Word characters, [0-9A-Za-z_]"},
+	{"xdigit", "Hex digit, [0-9A-Fa-f]"},
+}
+
+// categoryNames describes the Unicode general categories.
+var categoryNames = map[string]string{
+	"C": "Other", "Cc": "Control", "Cf": "Format", "Co": "Private use", "Cs": "Surrogate",
+	"L": "Letter", "Ll": "Lowercase letter", "Lm": "Modifier letter", "Lo": "Other letter", "Lt": "Titlecase letter", "Lu": "Uppercase letter",
+	"M": "Mark", "Mc": "Spacing mark", "Me": "Enclosing mark", "Mn": "Nonspacing mark",
+	"N": "Number", "Nd": "Decimal number", "Nl": "Letter number", "No": "Other number",
+	"P": "Punctuation", "Pc": "Connector punctuation", "Pd": "Dash punctuation", "Pe": "Close punctuation",
+	"Pf": "Final punctuation", "Pi": "Initial punctuation", "Po": "Other punctuation", "Ps": "Open punctuation",
+	"S": "Symbol", "Sc": "Currency symbol", "Sk": "Modifier symbol", "Sm": "Math symbol", "So": "Other symbol",
+	"Z": "Separator", "Zl": "Line separator", "Zp": "Paragraph separator", "Zs": "Space separator",
+}
+
+// SyntaxReference returns the complete reference, including every POSIX class and every
+// Unicode category and script known to the unicode package.
+func SyntaxReference() []SyntaxEntry {
+	entries := append([]SyntaxEntry(nil), syntaxBase...)
+
+	for _, class := range posixClasses {
+		syntax := "[[:" + class[0] + ":]]"
+		entries = append(entries, SyntaxEntry{SynPOSIX, syntax, class[1], syntax + "+", "Hello, World! 42\tend"})
+	}
+	entries = append(entries, SyntaxEntry{SynPOSIX, `[[:^alpha:]]`, "Negated ASCII class", `[[:^alpha:]]+`, "Hello, World! 42"})
+
+	for _, name := range sortedKeys(unicode.Categories) {
+		description := categoryNames[name]
+		if description == "" {
+			description = "Category " + name
+		}
+		entries = append(entries, unicodeEntry(SynUnicodeCat, name, description, unicode.Categories[name]))
+	}
+	for _, name := range sortedKeys(unicode.Scripts) {
+		entries = append(entries, unicodeEntry(SynUnicodeScr, name, name+" script", unicode.Scripts[name]))
+	}
+	entries = append(entries, SyntaxEntry{SynUnicodeCat, `\P{Greek}`, "Negated Unicode class", `\P{Greek}+`, "abc αβγ 123"})
+	return entries
+}
+
+func sortedKeys(tables map[string]*unicode.RangeTable) []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unicodeEntry builds the entry of a Unicode class, trying it on a few characters of the class mixed with ASCII.
+// The characters may not be printable, e.g. for the control and format categories.
+func unicodeEntry(category, name, description string, table *unicode.RangeTable) SyntaxEntry {
+	syntax := `\p{` + name + `}`
+	if len(name) == 1 {
+		syntax = `\p` + name
+	}
+	return SyntaxEntry{category, syntax, description, syntax + "+", fmt.Sprintf("abc %s 123", string(sampleRunes(table, 6)))}
+}
+
+// sampleRunes returns up to n runes of the table, the first of each of its ranges.
+func sampleRunes(table *unicode.RangeTable, n int) []rune {
+	var runes []rune
+	add := func(r rune) {
+		if len(runes) < n && utf8.ValidRune(r) {
+			runes = append(runes, r)
+		}
+	}
+	for _, r := range table.R16 {
+		add(rune(r.Lo))
+	}
+	for _, r := range table.R32 {
+		add(rune(r.Lo))
+	}
+	return runes
+}
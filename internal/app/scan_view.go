@@ -0,0 +1,68 @@
+package app
+
+import (
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// PickScanResult shows the scan results in a table and lets the user pick one.
+// It returns false if the user quit without picking.
+func PickScanResult(results []ScanResult) (ScanResult, bool, error) {
+	app := tview.NewApplication()
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0).
+		SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkCyan))
+	table.SetBorder(true).SetTitle(TitleScan)
+
+	headers := []string{"Location", "Func", "Pattern", "Status"}
+	for i, header := range headers {
+		table.SetCell(0, i, tview.NewTableCell(header).SetSelectable(false).SetAlign(tview.AlignCenter).SetExpansion(1).SetBackgroundColor(tcell.ColorDarkBlue))
+	}
+	for i, r := range results {
+		status := "ok"
+		color := tcell.ColorGreen
+		if r.Err != nil {
+			status = r.Err.Error()
+			color = tcell.ColorRed
+		}
+		if r.ParseError() {
+			status = "parse error: " + status
+		}
+		pattern := strconv.Quote(r.Pattern)
+		table.SetCell(i+1, 0, tview.NewTableCell(r.Location()).SetExpansion(3))
+		table.SetCell(i+1, 1, tview.NewTableCell(r.Func).SetExpansion(1))
+		table.SetCell(i+1, 2, tview.NewTableCell(pattern[1:len(pattern)-1]).SetExpansion(8))
+		table.SetCell(i+1, 3, tview.NewTableCell(status).SetExpansion(3).SetTextColor(color))
+	}
+
+	var picked ScanResult
+	ok := false
+	table.SetSelectedFunc(func(row, column int) {
+		if row > 0 && row <= len(results) && !results[row-1].ParseError() {
+			picked = results[row-1]
+			ok = true
+			app.Stop()
+		}
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc, event.Rune() == 'q':
+			app.Stop()
+			return nil
+		case event.Rune() == 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case event.Rune() == 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		}
+		return event
+	})
+	table.Select(1, 0)
+
+	if err := app.SetRoot(table, true).Run(); err != nil {
+		return ScanResult{}, false, err
+	}
+	return picked, ok, nil
+}
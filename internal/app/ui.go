@@ -1,10 +1,8 @@
 package app
 
 import (
-	"fmt"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
-	"strings"
 )
 
 // setupUI configures the layout and appearance of the UI components.
@@ -15,6 +13,8 @@ func (a *App) setupUI() {
 	a.regexInput.SetTitle(TitleRegex)
 	a.regexInput.SetFieldBackgroundColor(tcell.ColorDefault) // Remove background color
 
+	a.regexCompleter = newCompleter(a.regexInput, CompletePattern)
+
 	// Configure Text Area
 	a.textArea.SetBorder(true)
 	a.textArea.SetTitle(TitleText)
@@ -30,6 +30,27 @@ func (a *App) setupUI() {
 	a.matchView.SetTitle(TitleMatches)
 	a.matchView.SetScrollable(true)
 
+	// Configure Match Table, shown instead of the match view in table mode
+	a.matchTable.SetBorder(true)
+	a.matchTable.SetSelectable(true, true)
+	a.matchTable.SetFixed(1, 0)
+	a.matchPages.AddPage(MatchListPage, a.matchView, true, true)
+	a.matchPages.AddPage(MatchTablePage, a.matchTable, true, false)
+
+	// Configure Test Suite View (shown on demand)
+	a.suiteView.SetBorder(true)
+	a.suiteView.SetTitle(TitleTests)
+	a.suiteView.SetDynamicColors(true)
+	a.suiteView.SetScrollable(true)
+
+	// Configure Frequency and Statistics Views (shown on demand)
+	a.freqView.SetBorder(true)
+	a.freqView.SetScrollable(true)
+	a.statsView.SetBorder(true)
+	a.statsView.SetScrollable(true)
+	a.timelineList.SetBorder(true)
+	a.timelineList.ShowSecondaryText(false)
+
 	// Configure Status Bar components
 	a.helpHintView.SetText(HintHelp) // Updated hint text
 
@@ -38,14 +59,14 @@ func (a *App) setupUI() {
 		AddItem(a.helpHintView, 100, 1, false) // Adjusted width for new hint
 
 	// Configure Flex Layout for the main page
-	bottomPane := tview.NewFlex().
+	a.bottomPane = tview.NewFlex().
 		AddItem(a.highlightedView, 0, 1, false).
-		AddItem(a.matchView, 0, 1, false)
+		AddItem(a.matchPages, 0, 1, false)
 
 	a.flex = tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(a.regexInput, 3, 1, true).
 		AddItem(a.textArea, 0, 3, true).
-		AddItem(bottomPane, 0, 2, false).
+		AddItem(a.bottomPane, 0, 2, false).
 		AddItem(statusBar, 1, 0, false)
 
 	// --- Create Main Page ---
@@ -56,8 +77,26 @@ func (a *App) setupUI() {
 	// F1 Help Page (Keybindings)
 	a.keybindingsModal = a.createHelpModal()
 
-	// F3 Help Page (Regex)
-	a.setupHelpPage()
+	// F2 Help Page (Regex)
+	a.libraryView = NewLibraryView(func(p tview.Primitive) {
+		a.app.SetFocus(p)
+	})
+	a.libraryView.InitData(RegexHelpData.Entries())
+	a.libraryView.SetOnSelect(a.insertLibraryPattern)
+	a.libraryView.SetOnClose(func() {
+		a.modalPages.RemovePage(RegexHelpPage)
+		a.app.SetFocus(a.regexInput)
+	})
+
+	// F12 Syntax Reference Page
+	a.referenceView = NewReferenceView(func(p tview.Primitive) {
+		a.app.SetFocus(p)
+	})
+	a.referenceView.SetOnTry(a.trySyntaxExample)
+	a.referenceView.SetOnClose(func() {
+		a.modalPages.RemovePage(ReferencePage)
+		a.app.SetFocus(a.regexInput)
+	})
 
 	// F3 History Page
 	a.historyView = NewHistoryView(func(p tview.Primitive) {
@@ -90,32 +129,81 @@ func (a *App) setupUI() {
 		AddItem(tview.NewFlex().
 			AddItem(nil, 0, 1, false).
 			AddItem(a.exportForm, 80, 0, true).
-			AddItem(nil, 0, 1, false), 0, 1, true).
+			AddItem(nil, 0, 1, false), 19, 0, true).
 		AddItem(nil, 0, 1, false)
 
+	// F4 Refactor Page
+	a.refactorMenu = a.createRefactorMenu()
+	a.refactorPage = centered(a.refactorMenu, 60, 10)
+	a.prettyView = tview.NewTextView()
+	a.prettyView.SetBorder(true).SetTitle(TitlePretty)
+	a.prettyView.SetScrollable(true)
+
+	// F5 Import Page
+	a.importForm = a.createImportForm()
+	a.importPage = centered(a.importForm, 80, 9)
+
+	// Code Generation Page
+	a.setupCodePage()
+
+	// F6 Synthesis Page, kept above the bottom pane so that the preview stays visible
+	a.synthList = tview.NewList().ShowSecondaryText(false)
+	a.synthList.SetBorder(true).SetTitle(TitleSynth).SetTitleAlign(tview.AlignLeft)
+	a.synthPage = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(a.synthList, 0, 4, true).
+			AddItem(nil, 0, 1, false), 0, 3, true).
+		AddItem(nil, 0, 2, false)
+
+	// Additional Patterns Page, kept above the bottom pane so that the highlights stay visible
+	a.patternList = tview.NewList().ShowSecondaryText(false)
+	a.patternList.SetBorder(true).SetTitle(TitlePatterns).SetTitleAlign(tview.AlignLeft)
+	a.patternList.SetInputCapture(a.handlePatternListKey)
+	a.patternPage = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(a.patternList, 0, 4, true).
+			AddItem(nil, 0, 1, false), 0, 3, true).
+		AddItem(nil, 0, 2, false)
+
+	// Text Processing Options
+	a.optionsForm = a.createOptionsForm()
+	a.optionsPage = centered(a.optionsForm, 80, 15)
+
+	// F11 Tutorial Exercises, with the tutorial panel shown below the matches during an exercise
+	a.exerciseList = tview.NewList()
+	a.exerciseList.SetBorder(true).SetTitle(TitleExercises)
+	a.exercisePage = centered(a.exerciseList, 70, 2*len(Exercises)+4)
+	a.tutorialView.SetBorder(true)
+	a.tutorialView.SetDynamicColors(true)
+	a.tutorialView.SetScrollable(true)
+	a.tutorialView.SetWordWrap(true)
+
 	// Modal pages holder (for popups over everything)
 	// This now only contains the main page initially.
 	a.modalPages.AddPage(MainPage, a.pages, true, true)
 }
 
-func (a *App) setupHelpPage() {
-	var builder strings.Builder
-	builder.WriteString("[yellow]Common Patterns\n")
-	builder.WriteString("[yellow]---------------\n")
-	for _, item := range RegexHelpData.Common {
-		builder.WriteString(fmt.Sprintf("[green]%-20s [white]%s\n", item.Title, tview.Escape(item.Pattern)))
-	}
-	builder.WriteString("\n[yellow]Basic Escape Characters\n")
-	builder.WriteString("[yellow]-----------------------\n")
-	for _, item := range RegexHelpData.Escapes {
-		builder.WriteString(fmt.Sprintf("[green]%-20s [white]%s\n", item.Title, tview.Escape(item.Pattern)))
-	}
+func (a *App) createOptionsForm() *tview.Form {
+	form := tview.NewForm().
+		AddDropDown(LabelRecords, RecordModeOptions, 0, nil).
+		AddInputField(LabelSeparator, "", 50, nil, nil).
+		AddInputField(LabelFilter, "", 50, nil, nil).
+		AddDropDown(LabelPatternUse, PatternUseOptions, 0, nil).
+		AddInputField(LabelSplitLimit, "", 6, tview.InputFieldInteger, nil).
+		AddButton(ButtonApply, a.handleOptions).
+		AddButton(ButtonPipeline, func() {
+			a.modalPages.RemovePage(OptionsPage)
+			a.showPipelinePage(a.stages)
+		}).
+		AddButton(ButtonCancel, func() {
+			a.modalPages.RemovePage(OptionsPage)
+			a.app.SetFocus(a.regexInput)
+		})
 
-	a.helpView.SetText(builder.String())
-	a.helpView.SetTitle("Regex Patterns Help (Press F2 or Esc to close)")
-	a.helpView.SetBorder(true)
-	a.helpView.SetDynamicColors(true)
-	a.helpView.SetScrollable(true)
+	form.SetBorder(true).SetTitle(TitleOptions).SetTitleAlign(tview.AlignLeft)
+	return form
 }
 
 func (a *App) createHelpModal() *tview.Modal {
@@ -152,16 +240,93 @@ func (a *App) showResultModal(message string, isError bool) {
 
 func (a *App) createExportForm() *tview.Form {
 	form := tview.NewForm().
-		AddDropDown(LabelExportFormat, []string{OptJsonAll, OptJsonGroups, OptCustom}, 2, nil).
+		AddDropDown(LabelExportFormat, []string{OptJsonAll, OptJsonGroups, OptCustom, OptCsv, OptTsv, OptLines, OptFieldsCsv, OptTableCsv, OptFrequencies, OptStats, OptTimelineCsv, OptNdjson, OptTemplate}, 2, nil).
 		AddInputField(LabelCustomFormat, "$1", 40, nil, nil).
+		AddInputField(LabelTemplate, DefaultTemplate, 0, nil, nil).
 		AddInputField(LabelGroupNumbers, "", 40, nil, nil).
+		AddDropDown(LabelHeaderRow, HeaderRowOptions, 0, nil).
 		AddDropDown(LabelOutputTarget, []string{TargetClipboard, TargetFile}, 0, nil).
 		AddInputField(LabelFilePath, "", 40, nil, nil).
 		AddButton(ButtonExport, a.handleExport).
+		AddButton(ButtonCode, func() {
+			a.modalPages.RemovePage(ExportPage)
+			a.showCodePage()
+		}).
 		AddButton(ButtonCancel, func() {
 			a.modalPages.RemovePage(ExportPage) // Use modalPages
 		})
 
+	customFormat := form.GetFormItemByLabel(LabelCustomFormat).(*tview.InputField)
+	a.formatCompleter = newCompleter(customFormat, func(before string) (int, []Completion) {
+		return CompleteFormat(before, a.GetRegexInput())
+	})
+	customFormat.SetChangedFunc(a.formatCompleter.track)
+	customFormat.SetInputCapture(a.formatCompleter.handleKey)
+
 	form.SetBorder(true).SetTitle(TitleExportOptions).SetTitleAlign(tview.AlignLeft)
 	return form
 }
+
+func (a *App) createRefactorMenu() *tview.List {
+	list := tview.NewList().ShowSecondaryText(false)
+	for _, action := range []string{ActNonCapturing, ActCapturing, ActNameGroups, ActAlternations, ActMinify, ActPretty} {
+		list.AddItem(action, "", 0, nil)
+	}
+	list.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		a.handleRefactor(mainText)
+	})
+	list.SetBorder(true).SetTitle(TitleRefactor).SetTitleAlign(tview.AlignLeft)
+	return list
+}
+
+// centered wraps a primitive so that it is drawn in the middle of the screen with the given size.
+func centered(p tview.Primitive, width, height int) *tview.Flex {
+	return tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(p, width, 0, true).
+			AddItem(nil, 0, 1, false), height, 0, true).
+		AddItem(nil, 0, 1, false)
+}
+
+func (a *App) createImportForm() *tview.Form {
+	form := tview.NewForm().
+		AddDropDown(LabelDialect, Dialects, 0, nil).
+		AddInputField(LabelForeignRegex, "", 60, nil, nil).
+		AddButton(ButtonTranslate, a.handleImport).
+		AddButton(ButtonCancel, func() {
+			a.modalPages.RemovePage(ImportPage)
+			a.app.SetFocus(a.regexInput)
+		})
+
+	form.SetBorder(true).SetTitle(TitleImport).SetTitleAlign(tview.AlignLeft)
+	return form
+}
+
+func (a *App) setupCodePage() {
+	a.codeView = tview.NewTextView()
+	a.codeView.SetScrollable(true)
+	a.codeWarningsView = tview.NewTextView()
+	a.codeWarningsView.SetTextColor(tcell.ColorYellow)
+	a.codeWarningsView.SetBorder(true).SetTitle(TitleCodeWarnings)
+
+	a.codeForm = tview.NewForm().
+		AddDropDown(LabelLanguage, CodeLanguages, 0, func(option string, optionIndex int) {
+			a.updateCodeView(option)
+		}).
+		AddButton(ButtonCopy, a.handleCopyCode).
+		AddButton(ButtonClose, func() {
+			a.modalPages.RemovePage(CodePage)
+			a.app.SetFocus(a.regexInput)
+		})
+	a.codeForm.SetHorizontal(true)
+
+	body := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(a.codeForm, 3, 0, true).
+		AddItem(a.codeView, 0, 3, false).
+		AddItem(a.codeWarningsView, 0, 1, false)
+	body.SetBorder(true).SetTitle(TitleCode).SetTitleAlign(tview.AlignLeft)
+	a.codePage = centered(body, 100, 24)
+}
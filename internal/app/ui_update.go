@@ -2,41 +2,176 @@ package app
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-func (a *App) updateHighlightedView(text string, matches [][]int) {
-	a.highlightedMatchLines = make([]int, 0, len(matches))
-	colors := []string{"[white:green]", "[white:blue]"}
+func (a *App) updateHighlightedView(text string, spans []colorSpan) {
+	a.highlightedMatchLines = make([]int, 0, len(a.matchIndices))
+	for _, match := range a.matchIndices {
+		// Calculate line number for the match
+		// The number of newlines before the match start + 1
+		lineNumber := strings.Count(text[:match[0]], "\n")
+		a.highlightedMatchLines = append(a.highlightedMatchLines, lineNumber)
+	}
+	a.highlightedView.SetTitle(TitleHighlighted)
+	a.highlightedView.SetText(renderColorSpans(text, spans))
+}
+
+// updateLineView shows the selected lines with their context. Lines are selected by
+// lineMatches, the matches of all the patterns.
+func (a *App) updateLineView(text string, lineMatches [][]int, spans []colorSpan) {
+	opts := a.grepOpts
+	lines := GrepLines(text, lineMatches, opts)
+	inverted := ""
+	if opts.Invert {
+		inverted = TitleLinesInverted
+	}
+	a.highlightedView.SetTitle(fmt.Sprintf(TitleLinesFormat, len(lines), opts.Before, opts.After, inverted))
+	a.renderLines(text, lines, spans)
+}
+
+// updateFilterView shows the lines of the records, or the lines, that pass the filter.
+func (a *App) updateFilterView(text string, spans []colorSpan) {
+	unit := "lines"
+	total := len(textLines(text))
+	if a.records != nil {
+		unit = "records"
+		total = len(a.records)
+	}
+	a.highlightedView.SetTitle(fmt.Sprintf(TitleFilterFormat, len(a.searchUnits), total, unit))
+	a.renderLines(text, a.filterLines, spans)
+}
+
+// renderLines shows the lines with their numbers, like grep -n.
+func (a *App) renderLines(text string, lines []GrepLine, spans []colorSpan) {
+	a.grepLines = lines
+	if len(a.grepLines) == 0 {
+		a.highlightedView.SetText("(No lines)")
+		return
+	}
+
+	width := len(strconv.Itoa(a.grepLines[len(a.grepLines)-1].Number))
+	rows := make(map[int]int, len(a.grepLines)) // Line number to row in the view
 	var builder strings.Builder
-	lastIndex := 0
+	row := 0
+	next := 0 // First span that may be on the current line
+	for i, line := range a.grepLines {
+		if i > 0 && line.Number != a.grepLines[i-1].Number+1 {
+			builder.WriteString("[gray]--[-]\n")
+			row++
+		}
+		rows[line.Number] = row
 
+		// Clip the spans to the line.
+		for next < len(spans) && spans[next].End < line.Start {
+			next++
+		}
+		var clipped []colorSpan
+		for _, span := range spans[next:] {
+			if span.Start > line.End {
+				break
+			}
+			clipped = append(clipped, colorSpan{Span{max(span.Start, line.Start) - line.Start, min(span.End, line.End) - line.Start}, span.color})
+		}
+
+		color := "[gray]"
+		if line.Selected {
+			color = "[yellow]"
+		}
+		builder.WriteString(color + grepPrefix(line, width) + "[-]")
+		builder.WriteString(renderColorSpans(text[line.Start:line.End], clipped))
+		builder.WriteString("\n")
+		row++
+	}
+
+	// Matches are in order, so their line numbers can be counted incrementally.
+	a.highlightedMatchLines = make([]int, 0, len(a.matchIndices))
+	lineNumber, offset := 1, 0
+	for _, m := range a.matchIndices {
+		lineNumber += strings.Count(text[offset:m[0]], "\n")
+		offset = m[0]
+		a.highlightedMatchLines = append(a.highlightedMatchLines, rows[lineNumber])
+	}
+	a.highlightedView.SetText(builder.String())
+}
+
+// colorSpan is a part of the text to show with a color tag.
+type colorSpan struct {
+	Span
+	color string
+}
+
+// Highlight colors: the main pattern, then the additional ones in turn.
+var patternColors = []string{"[white:green]", "[white:blue]", "[white:purple]", "[black:aqua]", "[black:orange]", "[white:red]", "[black:fuchsia]"}
+
+// overlapColor marks the parts matched by more than one pattern.
+const overlapColor = "[black:yellow]"
+
+// highlightMatches marks the matches in the text with alternating background colors.
+func highlightMatches(text string, matches [][]int) string {
+	return renderColorSpans(text, alternatingSpans(matches))
+}
+
+// alternatingSpans colors the matches of one pattern alternately so that adjacent matches stand out.
+func alternatingSpans(matches [][]int) []colorSpan {
+	colors := []string{"[white:green]", "[white:blue]"}
+	spans := make([]colorSpan, len(matches))
 	for i, match := range matches {
-		start, end := match[0], match[1]
-		color := colors[i%len(colors)]
+		spans[i] = colorSpan{Span{match[0], match[1]}, colors[i%len(colors)]}
+	}
+	return spans
+}
 
-		// Calculate line number for the match
-		// The number of newlines before the match start + 1
-		lineNumber := strings.Count(text[:start], "\n")
-		a.highlightedMatchLines = append(a.highlightedMatchLines, lineNumber)
+// patternColor returns the color of an additional pattern, by its index in the pattern list.
+func patternColor(entry int) string {
+	return patternColors[(entry+1)%len(patternColors)]
+}
+
+// segmentSpans colors each segment after its pattern, or as an overlap.
+func segmentSpans(segments []Segment, colors []string) []colorSpan {
+	spans := make([]colorSpan, len(segments))
+	for i, segment := range segments {
+		color := overlapColor
+		if len(segment.Patterns) == 1 {
+			color = colors[segment.Patterns[0]]
+		}
+		spans[i] = colorSpan{Span{segment.Start, segment.End}, color}
+	}
+	return spans
+}
 
-		builder.WriteString(tview.Escape(text[lastIndex:start]))
-		builder.WriteString(color)
-		builder.WriteString(tview.Escape(text[start:end]))
+// renderColorSpans escapes the text and wraps the spans in their colors. The spans must be
+// in order and must not overlap.
+func renderColorSpans(text string, spans []colorSpan) string {
+	var builder strings.Builder
+	lastIndex := 0
+
+	for _, span := range spans {
+		builder.WriteString(tview.Escape(text[lastIndex:span.Start]))
+		builder.WriteString(span.color)
+		builder.WriteString(tview.Escape(text[span.Start:span.End]))
 		builder.WriteString("[:-]")
 
-		lastIndex = end
+		lastIndex = span.End
 	}
 	builder.WriteString(tview.Escape(text[lastIndex:]))
-	a.highlightedView.SetText(builder.String())
+	return builder.String()
 }
 
 func (a *App) updateMatchView(matches [][]string) {
-	a.matchView.SetTitle(fmt.Sprintf(TitleMatchesFormat, len(matches)))
-	if len(matches) == 0 {
+	if a.records != nil {
+		a.matchView.SetTitle(fmt.Sprintf(TitleMatchesRecordsFormat, len(matches), len(GroupByRecord(matches, a.matchRecords)), len(a.records)))
+	} else {
+		a.matchView.SetTitle(fmt.Sprintf(TitleMatchesFormat, len(matches)))
+	}
+	if len(matches) == 0 && len(a.patternResults) == 0 {
 		a.matchView.SetText("(No matches)")
 		return
 	}
@@ -46,7 +181,28 @@ func (a *App) updateMatchView(matches [][]string) {
 	const maxLen = 80 // Max length for a match line
 	lineCounter := 0
 
+	if a.pipelineErr != nil {
+		builder.WriteString(fmt.Sprintf("Pipeline error: %v\n\n", a.pipelineErr))
+		lineCounter += 2
+	}
+
+	// With additional patterns, the matches are grouped per pattern, the main one first.
+	if len(a.patternResults) > 0 {
+		builder.WriteString(fmt.Sprintf("══ Main pattern (%d matches) ══\n", len(matches)))
+		lineCounter++
+	}
+
+	text := a.textArea.GetText()
+	recordLine, lineOffset := 1, 0 // Line of the start of the current record
 	for i, match := range matches {
+		// Record header
+		if a.matchRecords != nil && (i == 0 || a.matchRecords[i] != a.matchRecords[i-1]) {
+			record := a.records[a.matchRecords[i]]
+			recordLine += strings.Count(text[lineOffset:record.Start], "\n")
+			lineOffset = record.Start
+			builder.WriteString(fmt.Sprintf("── Record %d, line %d ──\n", a.matchRecords[i]+1, recordLine))
+			lineCounter++
+		}
 		a.matchViewLines = append(a.matchViewLines, lineCounter)
 
 		// Full match
@@ -76,10 +232,271 @@ func (a *App) updateMatchView(matches [][]string) {
 			}
 		}
 
+		// Matches of the next stages of the pipeline
+		if a.pipeline != nil {
+			lineCounter += writePipelineMatches(&builder, a.pipeline[i].Children, 1, maxLen)
+		}
+
 		// Add a blank line after each match block
 		builder.WriteString("\n")
 		lineCounter++
 	}
 
+	for _, r := range a.patternResults {
+		builder.WriteString(fmt.Sprintf("══ Pattern %d: %s (%d matches) ══\n", r.entry+1, a.patterns[r.entry].pattern, len(r.matches)))
+		for i, match := range r.matches {
+			text := strconv.Quote(match[0])
+			text = text[1 : len(text)-1]
+			if len(text) > maxLen {
+				text = text[:maxLen/2-2] + " ... " + text[len(text)-(maxLen/2-2):]
+			}
+			builder.WriteString(fmt.Sprintf("%d: %s\n", i, text))
+		}
+		builder.WriteString("\n")
+	}
+
 	a.matchView.SetText(builder.String())
 }
+
+// updateFieldsView shows the fields of split mode, under a header per record or line when
+// the text is not split as a whole.
+func (a *App) updateFieldsView(text string) {
+	count := 0
+	for _, row := range a.fields {
+		count += len(row)
+	}
+	a.matchView.SetTitle(fmt.Sprintf(TitleFieldsFormat, count))
+
+	var builder strings.Builder
+	line, lineOffset := 1, 0
+	for i, row := range a.fields {
+		unit := a.fieldUnits[i]
+		line += strings.Count(text[lineOffset:unit.Start], "\n")
+		lineOffset = unit.Start
+		switch {
+		case a.records != nil:
+			record := sort.Search(len(a.records), func(r int) bool { return a.records[r].Start >= unit.Start })
+			builder.WriteString(fmt.Sprintf("── Record %d, line %d ──\n", record+1, line))
+		case a.searchUnits != nil:
+			builder.WriteString(fmt.Sprintf("── Line %d ──\n", line))
+		}
+		for j, field := range row {
+			field = strconv.Quote(field)
+			builder.WriteString(fmt.Sprintf("%d: %s\n", j+1, field[1:len(field)-1]))
+		}
+		builder.WriteString("\n")
+	}
+	a.matchView.SetText(builder.String())
+}
+
+// updateMatchTable shows the visible rows of the matches in the table.
+func (a *App) updateMatchTable() {
+	a.table, _ = NewResultTable(a.GetRegexInput(), a.matches) // The pattern compiled already.
+	a.tableRows = a.table.Visible(a.tableOpts)
+	a.matchTable.SetTitle(fmt.Sprintf(TitleTableFormat, len(a.tableRows), len(a.matches)))
+
+	a.matchTable.Clear()
+	for column, header := range a.table.Headers {
+		if column == a.tableOpts.SortColumn {
+			header += map[bool]string{false: " ▲", true: " ▼"}[a.tableOpts.Descending]
+		}
+		if a.tableOpts.Filters[column] != nil {
+			header += " *"
+		}
+		a.matchTable.SetCell(0, column, tview.NewTableCell(tview.Escape(header)).SetSelectable(false).SetAlign(tview.AlignCenter).SetBackgroundColor(tcell.ColorDarkBlue))
+	}
+	for i, row := range a.tableRows {
+		for column, cell := range row {
+			text := strconv.Quote(cell)
+			tableCell := tview.NewTableCell(tview.Escape(text[1 : len(text)-1])).SetMaxWidth(40)
+			if IsNumber(cell) {
+				tableCell.SetAlign(tview.AlignRight)
+			}
+			a.matchTable.SetCell(i+1, column, tableCell)
+		}
+	}
+}
+
+// groupLabel names a group of the pattern in the title of a panel. A group the pattern
+// lost is reset to the whole match.
+func (a *App) groupLabel(group *int) string {
+	re, err := regexp.Compile(a.GetRegexInput())
+	if err != nil {
+		return "the match"
+	}
+	if *group > re.NumSubexp() {
+		*group = 0
+	}
+	if *group == 0 {
+		return "the match"
+	}
+	if name := re.SubexpNames()[*group]; name != "" {
+		return "group " + name
+	}
+	return fmt.Sprintf("group %d", *group)
+}
+
+// updateFreqView counts the values of the chosen group of the matches.
+func (a *App) updateFreqView() {
+	group := a.groupLabel(&a.freqGroup)
+	entries := Frequencies(a.matches, a.freqGroup, a.freqByValue)
+	a.freqView.SetTitle(fmt.Sprintf(TitleFreqFormat, group, len(entries)))
+	a.freqView.SetText(RenderFrequencies(entries))
+}
+
+// updateStatsView summarises the numbers of the chosen group of the matches.
+func (a *App) updateStatsView() {
+	a.statsView.SetTitle(fmt.Sprintf(TitleStatsFormat, a.groupLabel(&a.statsGroup)))
+	a.statsView.SetText(RenderStats(NumericStats(a.matches, a.statsGroup)))
+}
+
+// updateTimelineView shows a bar per bucket of the timeline, the selected one marked.
+func (a *App) updateTimelineView() {
+	title := fmt.Sprintf(TitleTimelineFormat, a.groupLabel(&a.timelineOpts.Group),
+		BucketNames[slices.Index(BucketSizes, a.timelineOpts.Bucket)])
+	if a.timeline.Skipped > 0 {
+		title += fmt.Sprintf(TitleTimelineSkipped, a.timeline.Skipped)
+	}
+	a.timelineList.SetTitle(title)
+
+	current := a.timelineList.GetCurrentItem()
+	a.timelineList.Clear()
+	if a.timelineErr != nil {
+		a.timelineList.AddItem("[red]"+tview.Escape(a.timelineErr.Error())+"[-]", "", 0, nil)
+		return
+	}
+	most := 1
+	for _, bucket := range a.timeline.Buckets {
+		most = max(most, bucket.Count)
+	}
+	for _, bucket := range a.timeline.Buckets {
+		marker := "  "
+		if bucket.Start.Equal(a.timelineBucket) {
+			marker = "[yellow]▶[-] "
+		}
+		bar := strings.Repeat("█", bucket.Count*freqBarWidth/most)
+		a.timelineList.AddItem(fmt.Sprintf("%s%s %s %d", marker, FormatBucket(bucket.Start, a.timelineOpts.Bucket), bar, bucket.Count), "", 0, nil)
+	}
+	a.timelineList.SetCurrentItem(current)
+}
+
+// updateBucketView shows the lines of the matches in the selected bucket of the timeline.
+func (a *App) updateBucketView(text string, spans []colorSpan) {
+	a.highlightedView.SetTitle(fmt.Sprintf(TitleBucketFormat, FormatBucket(a.timelineBucket, a.timelineOpts.Bucket), len(a.matches)))
+	a.renderLines(text, GrepLines(text, a.matchIndices, GrepOptions{}), spans)
+}
+
+// writePipelineMatches writes the matches of a pipeline stage under the match they were
+// found in, indented by depth, and returns the number of lines written.
+func writePipelineMatches(builder *strings.Builder, matches []PipelineMatch, depth, maxLen int) int {
+	indent := strings.Repeat("    ", depth)
+	clip := func(s string, width int) string {
+		s = strconv.Quote(s)
+		s = s[1 : len(s)-1] // Remove quotes
+		if len(s) > width {
+			s = s[:width/2-2] + " ... " + s[len(s)-(width/2-2):]
+		}
+		return s
+	}
+
+	lines := 0
+	for i, match := range matches {
+		builder.WriteString(fmt.Sprintf("%s↳ %d: %s\n", indent, i, clip(match.Groups[0], maxLen-len(indent))))
+		for j, group := range match.Groups[1:] {
+			builder.WriteString(fmt.Sprintf("%s      %d: %s\n", indent, j+1, clip(group, maxLen-len(indent)-4)))
+		}
+		lines += len(match.Groups)
+		lines += writePipelineMatches(builder, match.Children, depth+1, maxLen)
+	}
+	return lines
+}
+
+// updateSuiteView runs the loaded test suite against the pattern and shows pass/fail per sample.
+func (a *App) updateSuiteView(regexStr string) {
+	if a.suite == nil {
+		return
+	}
+	a.suiteView.SetTitle(TitleTests)
+	if regexStr == "" {
+		a.suiteView.SetText("")
+		return
+	}
+	results, err := RunSuite(*a.suite, regexStr)
+	if err != nil {
+		a.suiteView.SetText("[red]Invalid Regular Expression[-]")
+		return
+	}
+
+	var builder strings.Builder
+	for _, r := range results {
+		status := "[green]PASS[-]"
+		if !r.Passed {
+			status = "[red]FAIL[-]"
+		}
+		builder.WriteString(fmt.Sprintf("%s %-7s %s", status, r.Kind, tview.Escape(strconv.Quote(r.Input))))
+		if r.Detail != "" {
+			builder.WriteString(" [yellow]" + tview.Escape(r.Detail) + "[-]")
+		}
+		builder.WriteString("\n")
+	}
+	a.suiteView.SetTitle(fmt.Sprintf(TitleTestsFormat, len(results)-CountFailures(results), len(results)))
+	a.suiteView.SetText(builder.String())
+}
+
+// updateTutorialView checks the pattern against the current exercise and records it once solved.
+func (a *App) updateTutorialView(regexStr string) {
+	t := a.tutorial
+	if t == nil {
+		return
+	}
+	exercise := Exercises[t.index]
+	checks, solved, err := CheckExercise(exercise, regexStr)
+	t.solved = solved
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("[yellow]%d. %s[-]\n%s\n\n", t.index+1, tview.Escape(exercise.Title), tview.Escape(exercise.Goal)))
+	if err != nil {
+		builder.WriteString("[red]Invalid Regular Expression[-]\n")
+	}
+	for _, c := range checks {
+		status := "[green]✓[-]"
+		if !c.Passed {
+			status = "[red]✗[-]"
+		}
+		kind := "match    "
+		if !c.Match {
+			kind = "no match "
+		}
+		builder.WriteString(fmt.Sprintf("%s %s %s\n", status, kind, tview.Escape(strconv.Quote(c.Text))))
+	}
+	for _, hint := range exercise.Hints[:t.hints] {
+		builder.WriteString("\n[yellow]Hint:[-] " + tview.Escape(hint))
+	}
+	if t.hints > 0 {
+		builder.WriteString("\n")
+	}
+
+	if solved && !t.progress.Solved[exercise.ID] {
+		t.progress.Solved[exercise.ID] = true
+		if err := SaveTutorialProgress(t.progressPath, t.progress); err != nil {
+			builder.WriteString(fmt.Sprintf("\n[red]Could not save the progress: %s[-]", tview.Escape(err.Error())))
+		}
+	}
+	switch {
+	case solved && t.index+1 < len(Exercises):
+		builder.WriteString("\n[green]Solved![-] Press Enter for the next exercise.")
+	case solved:
+		builder.WriteString("\n[green]Solved! You have finished the tutorial.[-]")
+	case t.hints < len(exercise.Hints):
+		builder.WriteString(fmt.Sprintf("\nF10 for a hint (%d left).", len(exercise.Hints)-t.hints))
+	}
+
+	solvedCount := 0
+	for _, e := range Exercises {
+		if t.progress.Solved[e.ID] {
+			solvedCount++
+		}
+	}
+	a.tutorialView.SetTitle(fmt.Sprintf(TitleTutorialFormat, solvedCount, len(Exercises)))
+	a.tutorialView.SetText(builder.String())
+}
@@ -14,6 +14,19 @@ import (
 const historyEnvVar = "REGEX_FIND_HISTORY_FILE"
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "scan":
+			runScan(os.Args[2:])
+			return
+		case "test":
+			runTest(os.Args[2:])
+			return
+		case "match":
+			runMatch(os.Args[2:])
+			return
+		}
+	}
 
 	filePath := flag.String("file", "", "Path to a file to load.")
 	flag.StringVar(filePath, "f", "", "Path to a file to load.")
@@ -23,22 +36,27 @@ func main() {
 
 	historyFile := flag.String("history-file", "", fmt.Sprintf("Path to the history file. Overrides the %s environment variable.", historyEnvVar))
 
+	suitePath := flag.String("suite", "", "Path to a test suite file whose suite is checked live against the pattern.")
+	suiteName := flag.String("suite-name", "", "Name of the suite of the --suite file to check (default the first one).")
+
+	tutorial := flag.Bool("tutorial", false, "Start the interactive tutorial. Progress is saved next to the history file.")
+
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "A TUI tool for interactively developing and testing regular expressions.\n\n")
 		fmt.Fprintf(os.Stderr, "Input can be provided from a file (--file), clipboard (--clipboard), or stdin.\n")
 		fmt.Fprintf(os.Stderr, "Example: cat my_text.log | %s\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  scan [packages]  Find and validate regex literals in Go source (see '%s scan -h')\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  test FILE...     Run regex test suite files (see '%s test -h')\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  match [FILE...]  Search without the TUI, with filters and records (see '%s match -h')\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 	}
 
 	flag.Parse()
 
-	// Determine history file path
-	historyPath := *historyFile
-	if historyPath == "" {
-		historyPath = os.Getenv(historyEnvVar)
-	}
+	historyPath := resolveHistoryPath(*historyFile)
 
 	var initialText string
 
@@ -70,10 +88,64 @@ func main() {
 		initialText = string(bytes)
 	}
 
+	var suite *app.TestSuite
+	if *suitePath != "" {
+		file, err := app.LoadSuiteFile(*suitePath)
+		if err != nil {
+			log.Fatalf("Error loading test suite: %v", err)
+		}
+		picked, err := file.Suite(*suiteName)
+		if err != nil {
+			log.Fatalf("Error loading test suite: %s: %v", *suitePath, err)
+		}
+		if *suiteName == "" && len(file.Suites) > 1 {
+			log.Printf("Checking the first of the %d suites of %s, %q; choose another with --suite-name", len(file.Suites), *suitePath, picked.Name)
+		}
+		suite = &picked
+	}
+
+	runApp(initialText, historyPath, appOptions{suite: suite, tutorial: *tutorial})
+}
+
+// resolveHistoryPath determines the history file path. The flag takes precedence over the environment variable.
+func resolveHistoryPath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv(historyEnvVar)
+}
+
+// appOptions configures the TUI started by runApp.
+type appOptions struct {
+	pattern  string
+	suite    *app.TestSuite // Shown live, provides the pattern when none is given.
+	tutorial bool           // Start in the tutorial.
+}
+
+// runApp starts the TUI with the given text and options, and saves the history when it exits.
+func runApp(initialText, historyPath string, opts appOptions) {
 	appInstance, err := app.New(initialText, historyPath)
 	if err != nil {
 		log.Fatalf("Error initializing application: %v", err)
 	}
+	sections, err := app.LoadLibraries(app.LibraryPaths())
+	if err != nil {
+		log.Printf("Warning: could not load pattern library: %v", err)
+	}
+	appInstance.SetLibraries(sections)
+	pattern := opts.pattern
+	if opts.suite != nil {
+		if pattern == "" {
+			pattern = opts.suite.Pattern
+		}
+		appInstance.SetSuite(*opts.suite)
+	}
+	if pattern != "" {
+		appInstance.SetRegexInput(pattern)
+	}
+	if opts.tutorial {
+		appInstance.StartTutorial()
+	}
 
 	if err := appInstance.Run(); err != nil {
 		log.Fatalf("Error running application: %v", err)
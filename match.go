@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/zoroqi/regex-find/internal/app"
+)
+
+// runMatch implements the match command: it searches files or stdin without the TUI
+// and prints the matches in one of the export formats.
+func runMatch(args []string) {
+	flags := flag.NewFlagSet("match", flag.ExitOnError)
+	pattern := flags.String("e", "", "The pattern. Without it, the lines or records that pass the filter are printed.")
+	filter := flags.String("filter", "", "Only search the lines or records that satisfy the expression, e.g. '/ERROR/ AND NOT /healthcheck/i OR /panic/'.")
+	records := flags.String("records", "whole", "How the text is split into records: "+strings.Join(app.RecordModeNames, ", ")+".")
+	separator := flags.String("separator", "", "Regex of the record delimiter (-records delimiter) or of the start of a record (-records start).")
+	format := flags.String("o", "", fmt.Sprintf("Output format: %s, %s, %s, %s, %s, %s, %s, %s, %s or %s (default %s with -e, else %s).\n"+
+		"%s is written as the lines are read, with the file name, offsets, line and column of each match.",
+		app.FormatLines, app.FormatJSON, app.FormatGroups, app.FormatCustom, app.FormatTemplate, app.FormatCSV, app.FormatTSV, app.FormatNDJSON,
+		app.FormatCounts, app.FormatStats, app.FormatCustom, app.FormatLines, app.FormatNDJSON))
+	groups := flags.String("groups", "", "Comma-separated group numbers for -o groups, or the columns of -o csv and tsv (default all).")
+	header := flags.String("header", "names", "Header row of -o csv and tsv: "+strings.Join(app.HeaderModeNames, ", ")+".")
+	custom := flags.String("format", "$0", "Format string for -o custom, e.g. '$1 - $2'.")
+	tmpl := flags.String("template", app.DefaultTemplate, "Go text/template rendered for each match by -o template, with .Index, .Groups, .Named.NAME,\n"+
+		".Line and .Col, the helpers upper, lower, trim, replace, printf, json and default, and optional\n"+
+		"{{define \"header\"}} and {{define \"footer\"}} sections, which receive .Regex and .Matches.")
+	countGroup := flags.String("count", "", "Group number or name counted by -o counts (default the whole match).")
+	countSort := flags.String("sort", "count", "Order of -o counts: count or value.")
+	statGroup := flags.String("stat", "", "Group number or name summarised by -o stats (default the whole match).\n"+
+		"Its values are numbers, durations (12ms, 1.5s) or sizes (512B, 2KB).")
+	var stages []app.Stage
+	flags.Func("stage", "Pipeline stage GROUP:PATTERN searching a group of each match of the previous stage,\n"+
+		"e.g. '1:(\\w+)=(\\w+)', or ':PATTERN' for the whole match. Repeatable; nested in -o json and groups.", func(s string) error {
+		stage, err := app.ParseStage(s)
+		stages = append(stages, stage)
+		return err
+	})
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s match [options] [FILE...]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Searches the files, concatenated, or stdin like the TUI does, and prints the result.\n")
+		fmt.Fprintf(os.Stderr, "Example: %s match -filter '/ERROR/ AND NOT /healthcheck/' -e 'user=(\\w+)' -format '$1' app.log\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	mode, err := app.ParseRecordMode(*records)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *countSort != "count" && *countSort != "value" {
+		log.Fatalf("Error: unknown order %q, expected count or value", *countSort)
+	}
+	headerMode := slices.Index(app.HeaderModeNames, *header)
+	if headerMode < 0 {
+		log.Fatalf("Error: unknown header %q, expected one of %s", *header, strings.Join(app.HeaderModeNames, ", "))
+	}
+	if *format == "" {
+		*format = app.FormatLines
+		if *pattern != "" {
+			*format = app.FormatCustom
+		}
+	}
+
+	opts := app.HeadlessOptions{
+		Pattern:  *pattern,
+		Filter:   *filter,
+		Records:  app.RecordOptions{Mode: mode, Separator: *separator},
+		Format:   *format,
+		Groups:   *groups,
+		Header:   app.HeaderMode(headerMode),
+		Custom:   *custom,
+		Template: *tmpl,
+		Stages:   stages,
+
+		CountGroup:   *countGroup,
+		CountByValue: *countSort == "value",
+		StatGroup:    *statGroup,
+	}
+	if *format == app.FormatNDJSON {
+		streamNDJSON(flags.Args(), opts)
+		return
+	}
+
+	var text strings.Builder
+	if flags.NArg() == 0 {
+		bytes, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatalf("Error reading from stdin: %v", err)
+		}
+		text.Write(bytes)
+	}
+	for _, path := range flags.Args() {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Error reading file %s: %v", path, err)
+		}
+		// Keep the last line of a file from running into the first line of the next one.
+		if text.Len() > 0 && !strings.HasSuffix(text.String(), "\n") {
+			text.WriteByte('\n')
+		}
+		text.Write(bytes)
+	}
+
+	output, err := app.RunHeadless(text.String(), opts)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	os.Stdout.Write(output)
+	if len(output) > 0 && output[len(output)-1] != '\n' {
+		fmt.Println()
+	}
+}
+
+// streamNDJSON writes the matches of the files, or of stdin, to stdout as they are found.
+// Unlike the other formats, the files aren't concatenated: offsets and lines restart with
+// each file, whose name is written with its matches.
+func streamNDJSON(paths []string, opts app.HeadlessOptions) {
+	writer, err := app.NewHeadlessNDJSON(os.Stdout, opts)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if len(paths) == 0 {
+		if err := writer.Stream(os.Stdin); err != nil {
+			log.Fatalf("Error reading from stdin: %v", err)
+		}
+	}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Error reading file %s: %v", path, err)
+		}
+		writer.File = path
+		err = writer.Stream(file)
+		file.Close()
+		if err != nil {
+			log.Fatalf("Error reading file %s: %v", path, err)
+		}
+	}
+}
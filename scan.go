@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/zoroqi/regex-find/internal/app"
+)
+
+// runScan implements the scan command: it lists the regex literals of Go source trees
+// and opens the picked one in the TUI.
+func runScan(args []string) {
+	flags := flag.NewFlagSet("scan", flag.ExitOnError)
+	list := flags.Bool("list", false, "Print the patterns instead of picking one. Exits with status 1 if any pattern is invalid\n"+
+		"or any file can't be parsed.")
+	flags.BoolVar(list, "l", false, "Print the patterns instead of picking one.")
+	filePath := flags.String("file", "", "Path to a file to load as text when a pattern is opened.")
+	flags.StringVar(filePath, "f", "", "Path to a file to load as text when a pattern is opened.")
+	historyFile := flags.String("history-file", "", fmt.Sprintf("Path to the history file. Overrides the %s environment variable.", historyEnvVar))
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s scan [options] [packages]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Finds regexp.MustCompile/Compile/MatchString calls with constant patterns and validates them.\n")
+		fmt.Fprintf(os.Stderr, "Packages are directories, files or directories ending in /... (default: .).\n")
+		fmt.Fprintf(os.Stderr, "Example: %s scan ./...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	results, err := app.ScanGoSources(flags.Args())
+	if err != nil {
+		log.Fatalf("Error scanning sources: %v", err)
+	}
+
+	stat, _ := os.Stdout.Stat()
+	if *list || (stat.Mode()&os.ModeCharDevice) == 0 {
+		fmt.Print(app.FormatScanResults(results))
+		for _, r := range results {
+			if r.Err != nil {
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No regex literals found.")
+		return
+	}
+	picked, ok, err := app.PickScanResult(results)
+	if err != nil {
+		log.Fatalf("Error running application: %v", err)
+	}
+	if !ok {
+		return
+	}
+
+	var initialText string
+	if *filePath != "" {
+		bytes, readErr := os.ReadFile(*filePath)
+		if readErr != nil {
+			log.Fatalf("Error reading file %s: %v", *filePath, readErr)
+		}
+		initialText = string(bytes)
+	}
+	runApp(initialText, resolveHistoryPath(*historyFile), appOptions{pattern: picked.Pattern})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/zoroqi/regex-find/internal/app"
+)
+
+// runTest implements the test command: it runs test suite files without the TUI
+// and exits with status 1 if any case fails.
+func runTest(args []string) {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	verbose := flags.Bool("v", false, "Print every case, not only the failures.")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s test [options] FILE...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Runs regex test suite files. Each file is JSON of the form:\n")
+		fmt.Fprintf(os.Stderr, "  {\"suites\": [{\"name\": \"...\", \"pattern\": \"...\", \"match\": [...], \"noMatch\": [...],\n")
+		fmt.Fprintf(os.Stderr, "    \"captures\": [{\"input\": \"...\", \"groups\": {\"1\": \"...\", \"name\": \"...\"}}]}]}\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, path := range flags.Args() {
+		file, err := app.LoadSuiteFile(path)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		for _, suite := range file.Suites {
+			results, err := app.RunSuite(suite, suite.Pattern)
+			if err != nil {
+				fmt.Printf("FAIL %s: %s: invalid pattern: %v\n", path, suite.Name, err)
+				failed = true
+				continue
+			}
+			failures := app.CountFailures(results)
+			for _, r := range results {
+				if !r.Passed || *verbose {
+					status := "ok  "
+					if !r.Passed {
+						status = "FAIL"
+					}
+					fmt.Printf("    %s %s %s %s\n", status, r.Kind, strconv.Quote(r.Input), r.Detail)
+				}
+			}
+			status := "ok  "
+			if failures > 0 {
+				status = "FAIL"
+				failed = true
+			}
+			fmt.Printf("%s %s: %s (%d/%d passed)\n", status, path, suite.Name, len(results)-failures, len(results))
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}